@@ -0,0 +1,53 @@
+package devify
+
+import (
+	"context"
+
+	"github.com/jorgeSader/devify/session"
+)
+
+// Login marks the session in ctx as authenticated as userID.
+//
+// It renews the session token before storing the user ID, so that a token
+// planted in the browser before login (session fixation) is useless afterwards.
+// Always use Login rather than putting "userID" into the session directly.
+//
+// Example:
+//
+//	if err := d.Login(r.Context(), user.ID); err != nil {
+//	    d.ServerError(w, r, err)
+//	    return
+//	}
+func (d *Devify) Login(ctx context.Context, userID interface{}) error {
+	if err := d.Session.RenewToken(ctx); err != nil {
+		return err
+	}
+	d.Session.Put(ctx, session.UserIDKey, userID)
+	return nil
+}
+
+// Logout removes the user from the session in ctx and renews its token.
+// Other session data, such as flash messages, is kept.
+func (d *Devify) Logout(ctx context.Context) error {
+	d.Session.Remove(ctx, session.UserIDKey)
+	return d.Session.RenewToken(ctx)
+}
+
+// UserSessions lists the active sessions of userID. It is supported by the
+// SQL, Redis and in-memory session stores.
+func (d *Devify) UserSessions(ctx context.Context, userID interface{}) ([]session.Info, error) {
+	return session.UserSessions(ctx, d.Session, userID)
+}
+
+// LogoutEverywhere revokes every stored session of userID and returns how many
+// were revoked. When keepCurrent is true, the session in ctx is left signed in
+// (e.g., "log out other devices"); ctx must then be a request context. When
+// signing out the current request too, also call Logout so the in-flight
+// session is not committed again at the end of the request.
+func (d *Devify) LogoutEverywhere(ctx context.Context, userID interface{}, keepCurrent bool) (int, error) {
+	keep := ""
+	if keepCurrent {
+		keep = d.Session.Token(ctx)
+	}
+	return session.RevokeUserSessions(ctx, d.Session, userID, keep)
+}
@@ -0,0 +1,361 @@
+package devify
+
+import (
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// defaultMaxBodySize is the request body limit when MAX_BODY_SIZE is not set.
+const defaultMaxBodySize = 1 << 20 // 1MB
+
+// Bind fills the struct dst points to from r:
+//
+//   - the body, decoded by Content-Type: JSON (json tags), XML (xml tags),
+//     application/x-www-form-urlencoded and multipart/form-data (form tags,
+//     falling back to the json name, then the field name);
+//   - the query string, into fields tagged query:"name";
+//   - chi URL parameters, into fields tagged path:"name".
+//
+// Later sources override earlier ones. The body is limited to MAX_BODY_SIZE
+// bytes (1MB by default), or MAX_UPLOAD_SIZE for multipart forms; w is used
+// to close the connection after an oversized body. Form, query and path values are converted to
+// strings, bools, numbers, time.Time (RFC 3339 or 2006-01-02), time.Duration,
+// encoding.TextUnmarshaler, pointers and slices of those.
+//
+// Errors caused by the request are *Error values, ready for WriteError: 400
+// for a malformed body, 413 when it is too large, 415 for an unsupported
+// Content-Type and 422 with Fields, keyed like Validation.Errors, when values
+// have the wrong type.
+//
+// Example:
+//
+//	var input struct {
+//	    ID    int    `path:"id"`
+//	    Page  int    `query:"page"`
+//	    Email string `json:"email" form:"email"`
+//	}
+//	if err := d.Bind(w, r, &input); err != nil {
+//	    d.WriteError(w, r, err)
+//	    return
+//	}
+func (d *Devify) Bind(w http.ResponseWriter, r *http.Request, dst interface{}) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("devify: Bind needs a non-nil pointer to a struct, got %T", dst)
+	}
+	v := rv.Elem()
+	fields := make(map[string]string)
+
+	if hasBody(r) {
+		if err := d.bindBody(w, r, dst, v, fields); err != nil {
+			return err
+		}
+	}
+
+	query := r.URL.Query()
+	if err := d.bindValues(r, v, "query", false, func(name string) []string { return query[name] }, fields); err != nil {
+		return err
+	}
+
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		lookup := func(name string) []string {
+			for i, key := range rctx.URLParams.Keys {
+				if key == name {
+					return []string{rctx.URLParams.Values[i]}
+				}
+			}
+			return nil
+		}
+		if err := d.bindValues(r, v, "path", false, lookup, fields); err != nil {
+			return err
+		}
+	}
+
+	if len(fields) > 0 {
+		return &Error{
+			Status:  http.StatusUnprocessableEntity,
+			Code:    "invalid_fields",
+			Message: d.bindMessage(r, "failed", "The given data was invalid."),
+			Fields:  fields,
+		}
+	}
+	return nil
+}
+
+// ReadXML reads a single XML document from the request body into data. The
+// body is limited to MAX_BODY_SIZE bytes (1MB by default).
+//
+// Example:
+//
+//	var u User
+//	if err := d.ReadXML(w, r, &u); err != nil {
+//	    // Handle error
+//	}
+func (d *Devify) ReadXML(w http.ResponseWriter, r *http.Request, data interface{}) error {
+	r.Body = http.MaxBytesReader(w, r.Body, d.maxBodySize())
+
+	if err := xml.NewDecoder(r.Body).Decode(data); err != nil {
+		if err == io.EOF {
+			return errors.New("empty XML body not allowed")
+		}
+		return fmt.Errorf("failed to decode XML: %w", err)
+	}
+	return nil
+}
+
+// maxBodySize returns the configured request body limit.
+func (d *Devify) maxBodySize() int64 {
+	if d.config.maxBodySize > 0 {
+		return d.config.maxBodySize
+	}
+	return defaultMaxBodySize
+}
+
+// limitBody caps the body of r at MAX_UPLOAD_SIZE for multipart requests and
+// at MAX_BODY_SIZE otherwise. w may be nil, but only with w does the server
+// close the connection after an oversized body.
+func (d *Devify) limitBody(w http.ResponseWriter, r *http.Request) {
+	limit := d.maxBodySize()
+	if isMultipart(r) {
+		limit = d.maxUploadSize()
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, limit)
+}
+
+// isMultipart reports whether r carries a multipart/form-data body.
+func isMultipart(r *http.Request) bool {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return mediaType == "multipart/form-data"
+}
+
+// bodyError converts an error reading the request body into an *Error: 413
+// when the body exceeded its limit and 400 otherwise.
+func bodyError(err error) error {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return NewError(http.StatusRequestEntityTooLarge, "body_too_large",
+			fmt.Sprintf("The request body must not exceed %d bytes.", tooLarge.Limit)).Wrap(err)
+	}
+	return NewError(http.StatusBadRequest, "malformed_body", "The request body could not be decoded.").Wrap(err)
+}
+
+// hasBody reports whether r carries a request body.
+func hasBody(r *http.Request) bool {
+	return r.Body != nil && r.Body != http.NoBody && r.ContentLength != 0
+}
+
+// bindBody decodes the body of r into dst according to its Content-Type.
+func (d *Devify) bindBody(w http.ResponseWriter, r *http.Request, dst interface{}, v reflect.Value, fields map[string]string) error {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	d.limitBody(w, r)
+
+	var err error
+	switch {
+	case mediaType == mediaJSON || strings.HasSuffix(mediaType, "+json"):
+		dec := json.NewDecoder(r.Body)
+		if err = dec.Decode(dst); err == nil && dec.More() {
+			err = errors.New("body must only have a single JSON value")
+		}
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field != "" {
+			fields[typeErr.Field] = d.kindMessage(r, typeErr.Type)
+			return nil
+		}
+	case mediaType == mediaXML || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"):
+		err = xml.NewDecoder(r.Body).Decode(dst)
+	case mediaType == "application/x-www-form-urlencoded":
+		if err = r.ParseForm(); err == nil {
+			return d.bindValues(r, v, "form", true, func(name string) []string { return r.PostForm[name] }, fields)
+		}
+	case mediaType == "multipart/form-data":
+		if err = r.ParseMultipartForm(d.maxUploadSize()); err == nil {
+			return d.bindValues(r, v, "form", true, func(name string) []string { return r.MultipartForm.Value[name] }, fields)
+		}
+	default:
+		return NewError(http.StatusUnsupportedMediaType, "unsupported_media_type",
+			fmt.Sprintf("Content-Type %q is not supported.", mediaType))
+	}
+
+	if err != nil {
+		return bodyError(err)
+	}
+	return nil
+}
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	timeType            = reflect.TypeOf(time.Time{})
+	durationType        = reflect.TypeOf(time.Duration(0))
+)
+
+// bindValues sets the fields of v tagged with tag from the values lookup
+// returns. With jsonFallback, untagged fields use their json name or, failing
+// that, their Go name. Conversion failures are added to fields.
+func (d *Devify) bindValues(r *http.Request, v reflect.Value, tag string, jsonFallback bool, lookup func(string) []string, fields map[string]string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := field.Tag.Get(tag)
+		if name == "" && field.Anonymous && field.Type.Kind() == reflect.Struct {
+			if err := d.bindValues(r, v.Field(i), tag, jsonFallback, lookup, fields); err != nil {
+				return err
+			}
+			continue
+		}
+		if name == "" && jsonFallback {
+			name, _, _ = strings.Cut(field.Tag.Get("json"), ",")
+			if name == "" {
+				name = field.Name
+			}
+		}
+		name, _, _ = strings.Cut(name, ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		values := lookup(name)
+		if len(values) == 0 {
+			continue
+		}
+		ok, err := setField(v.Field(i), values)
+		if err != nil {
+			return fmt.Errorf("devify: cannot bind %s.%s: %w", t.Name(), field.Name, err)
+		}
+		if !ok {
+			fields[name] = d.kindMessage(r, field.Type)
+		}
+	}
+	return nil
+}
+
+// setField sets f from values. It returns false when a value can't be
+// converted, and an error when f has a type Bind doesn't support.
+func setField(f reflect.Value, values []string) (bool, error) {
+	if f.Type() != timeType && !f.Addr().Type().Implements(textUnmarshalerType) {
+		switch f.Kind() {
+		case reflect.Pointer:
+			elem := reflect.New(f.Type().Elem())
+			ok, err := setField(elem.Elem(), values)
+			if ok && err == nil {
+				f.Set(elem)
+			}
+			return ok, err
+		case reflect.Slice:
+			slice := reflect.MakeSlice(f.Type(), len(values), len(values))
+			for i, value := range values {
+				if ok, err := setField(slice.Index(i), []string{value}); !ok || err != nil {
+					return ok, err
+				}
+			}
+			f.Set(slice)
+			return true, nil
+		}
+	}
+	return setScalar(f, values[0])
+}
+
+// setScalar converts value to the type of f and sets it.
+func setScalar(f reflect.Value, value string) (bool, error) {
+	switch {
+	case f.Type() == timeType:
+		value = strings.TrimSpace(value)
+		for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+			if parsed, err := time.Parse(layout, value); err == nil {
+				f.Set(reflect.ValueOf(parsed))
+				return true, nil
+			}
+		}
+		return false, nil
+	case f.Type() == durationType:
+		parsed, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			return false, nil
+		}
+		f.SetInt(int64(parsed))
+		return true, nil
+	}
+
+	if u, ok := f.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(value)) == nil, nil
+	}
+
+	value = strings.TrimSpace(value)
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(value)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return false, nil
+		}
+		f.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(value, 10, f.Type().Bits())
+		if err != nil {
+			return false, nil
+		}
+		f.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(value, 10, f.Type().Bits())
+		if err != nil {
+			return false, nil
+		}
+		f.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(value, f.Type().Bits())
+		if err != nil {
+			return false, nil
+		}
+		f.SetFloat(parsed)
+	default:
+		return false, fmt.Errorf("unsupported type %s", f.Type())
+	}
+	return true, nil
+}
+
+// kindMessage returns the validation message for a value that isn't a t.
+func (d *Devify) kindMessage(r *http.Request, t reflect.Type) string {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return d.bindMessage(r, "date", "Must be a valid date (e.g., YYYY-MM-DD).")
+	case t == durationType:
+		return d.bindMessage(r, "invalid", "Invalid value.")
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return d.bindMessage(r, "boolean", "Must be a valid boolean value (true, false, 1, or 0).")
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return d.bindMessage(r, "int", "Must be an integer.")
+	case reflect.Float32, reflect.Float64:
+		return d.bindMessage(r, "float", "Must be a number.")
+	default:
+		return d.bindMessage(r, "invalid", "Invalid value.")
+	}
+}
+
+// bindMessage translates a validation.<key> message like Validation does.
+func (d *Devify) bindMessage(r *http.Request, key, fallback string) string {
+	v := &Validation{Req: r, translator: d.I18n}
+	return v.message(key, fallback)
+}
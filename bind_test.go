@@ -0,0 +1,162 @@
+package devify
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type bindInput struct {
+	ID       int           `path:"id"`
+	Page     int           `query:"page"`
+	Tags     []string      `query:"tag"`
+	Name     string        `json:"name" xml:"name" form:"name"`
+	Email    string        `json:"email" xml:"email"` // bound from forms by its json name
+	Age      *int          `json:"age,omitempty" xml:"age" form:"age"`
+	Active   bool          `json:"active" xml:"active" form:"active"`
+	Born     time.Time     `json:"born" xml:"born" form:"born"`
+	Timeout  time.Duration `json:"-" xml:"-" form:"timeout"`
+	Internal string        `json:"-" xml:"-" form:"-"`
+}
+
+func TestBind(t *testing.T) {
+	age := 36
+	born := time.Date(1815, 12, 10, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		target      string
+		contentType string
+		body        string
+		want        bindInput
+		status      int // 0 when Bind succeeds
+		code        string
+		fields      []string
+	}{
+		{"JSON", "/items/7?page=2&tag=a&tag=b", "application/json",
+			`{"name":"Ada","email":"ada@example.com","age":36,"active":true,"born":"1815-12-10T00:00:00Z"}`,
+			bindInput{ID: 7, Page: 2, Tags: []string{"a", "b"}, Name: "Ada", Email: "ada@example.com", Age: &age, Active: true, Born: born}, 0, "", nil},
+		{"JSON with charset", "/items/7", "application/json; charset=utf-8", `{"name":"Ada"}`,
+			bindInput{ID: 7, Name: "Ada"}, 0, "", nil},
+		{"XML", "/items/7", "application/xml", `<input><name>Ada</name><age>36</age></input>`,
+			bindInput{ID: 7, Name: "Ada", Age: &age}, 0, "", nil},
+		{"form", "/items/7", "application/x-www-form-urlencoded",
+			"name=Ada&email=ada%40example.com&age=36&active=1&born=1815-12-10&timeout=90s&Internal=x",
+			bindInput{ID: 7, Name: "Ada", Email: "ada@example.com", Age: &age, Active: true, Born: born, Timeout: 90 * time.Second}, 0, "", nil},
+		{"query only fills query fields", "/items/7?name=Eve", "application/json", `{"name":"Ada"}`,
+			bindInput{ID: 7, Name: "Ada"}, 0, "", nil},
+		{"no body", "/items/7?page=3", "", "", bindInput{ID: 7, Page: 3}, 0, "", nil},
+
+		{"JSON type error", "/items/7", "application/json", `{"age":"old"}`,
+			bindInput{}, http.StatusUnprocessableEntity, "invalid_fields", []string{"age"}},
+		{"form type errors", "/items/7?page=x", "application/x-www-form-urlencoded", "age=x&active=on&born=someday&timeout=soon",
+			bindInput{}, http.StatusUnprocessableEntity, "invalid_fields", []string{"active", "age", "born", "page", "timeout"}},
+		{"path type error", "/items/seven", "", "", bindInput{}, http.StatusUnprocessableEntity, "invalid_fields", []string{"id"}},
+		{"malformed JSON", "/items/7", "application/json", `{"name":`,
+			bindInput{}, http.StatusBadRequest, "malformed_body", nil},
+		{"two JSON values", "/items/7", "application/json", `{"name":"Ada"}{"name":"Eve"}`,
+			bindInput{}, http.StatusBadRequest, "malformed_body", nil},
+		{"malformed XML", "/items/7", "application/xml", `<input><name>`,
+			bindInput{}, http.StatusBadRequest, "malformed_body", nil},
+		{"too large", "/items/7", "application/json", `{"name":"` + strings.Repeat("x", 2048) + `"}`,
+			bindInput{}, http.StatusRequestEntityTooLarge, "body_too_large", nil},
+		{"unsupported type", "/items/7", "text/plain", "Ada",
+			bindInput{}, http.StatusUnsupportedMediaType, "unsupported_media_type", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newTestApp(t)
+			d.config.maxBodySize = 1024
+			d.CSRFExempt("/items/*")
+
+			var got bindInput
+			var err error
+			d.Routes.Post("/items/{id}", func(w http.ResponseWriter, r *http.Request) {
+				err = d.Bind(w, r, &got)
+			})
+
+			var body io.Reader
+			if tt.body != "" {
+				body = strings.NewReader(tt.body)
+			}
+			r := httptest.NewRequest(http.MethodPost, tt.target, body)
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+			serve(d, r)
+
+			if tt.status == 0 {
+				if err != nil {
+					t.Fatalf("Bind error = %v", err)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("Bind = %+v; want %+v", got, tt.want)
+				}
+				return
+			}
+
+			var appErr *Error
+			if !errors.As(err, &appErr) {
+				t.Fatalf("Bind error = %v; want *Error", err)
+			}
+			if appErr.Status != tt.status || appErr.Code != tt.code {
+				t.Errorf("error = %d %s; want %d %s", appErr.Status, appErr.Code, tt.status, tt.code)
+			}
+			var fields []string
+			for field := range appErr.Fields {
+				fields = append(fields, field)
+			}
+			if len(fields) != len(tt.fields) {
+				t.Errorf("fields = %v; want %v", appErr.Fields, tt.fields)
+			}
+			for _, field := range tt.fields {
+				if appErr.Fields[field] == "" {
+					t.Errorf("no error for field %s in %v", field, appErr.Fields)
+				}
+			}
+		})
+	}
+}
+
+func TestBind_Multipart(t *testing.T) {
+	d := newTestApp(t)
+	d.config.maxBodySize = 1024
+	d.config.maxUploadSize = 1 << 20
+
+	var got bindInput
+	var err error
+	d.CSRFExempt("/items/*")
+	d.Routes.Post("/items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		err = d.Bind(w, r, &got)
+	})
+
+	// The file makes the body larger than MAX_BODY_SIZE but not MAX_UPLOAD_SIZE.
+	body, contentType := multipartBody(t, map[string]string{"name": "Ada"}, 4096)
+	r := httptest.NewRequest(http.MethodPost, "/items/7", body)
+	r.Header.Set("Content-Type", contentType)
+	serve(d, r)
+
+	if err != nil || got.Name != "Ada" || got.ID != 7 {
+		t.Errorf("Bind = %+v, %v", got, err)
+	}
+}
+
+func TestBind_InvalidDestination(t *testing.T) {
+	d := newTestApp(t)
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+
+	for _, dst := range []interface{}{nil, bindInput{}, (*bindInput)(nil), new(string)} {
+		err := d.Bind(w, r, dst)
+		var appErr *Error
+		if err == nil || errors.As(err, &appErr) {
+			t.Errorf("Bind(%T) error = %v; want a plain error", dst, err)
+		}
+	}
+}
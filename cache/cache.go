@@ -5,6 +5,7 @@ import (
 	"encoding/gob"
 	"fmt"
 	"log"
+	"strconv"
 	"strings"
 
 	"github.com/gomodule/redigo/redis"
@@ -32,9 +33,15 @@ type Cache interface {
 //
 // The Prefix field should be unique per application to prevent key collisions
 // when multiple applications share the same Redis instance.
+//
+// When HashTag is true the prefix is wrapped in braces (e.g., "{app1}:user") so
+// that every key of the application hashes to the same Redis Cluster slot. This
+// keeps multi-key commands such as the batched DEL in Empty valid on clustered
+// deployments and cluster-aware proxies.
 type RedisCache struct {
-	Conn   *redis.Pool // Redis connection pool
-	Prefix string      // Namespace prefix for all keys (e.g., "app1")
+	Conn    *redis.Pool // Redis connection pool
+	Prefix  string      // Namespace prefix for all keys (e.g., "app1")
+	HashTag bool        // Wrap the prefix in a cluster hash tag (e.g., "{app1}")
 }
 
 // Entry is a map used to store cache data as key-value pairs.
@@ -43,7 +50,7 @@ type Entry map[string]interface{}
 
 // Has checks if a key exists in the Redis cache.
 //
-// The key is prefixed with the RedisCache Prefix (e.g., "prefix:key").
+// The key is prefixed with the RedisCache namespace (e.g., "prefix:key").
 // It returns true if the key exists, false otherwise, and an error if the operation fails.
 //
 // Example:
@@ -52,7 +59,7 @@ type Entry map[string]interface{}
 //	exists, err := cache.Has("user")
 //	// Checks for "app1:user" in Redis
 func (c *RedisCache) Has(str string) (bool, error) {
-	key := fmt.Sprintf("%s:%s", c.Prefix, str)
+	key := c.key(str)
 	conn := c.Conn.Get()
 	defer func() {
 		if err := conn.Close(); err != nil {
@@ -67,6 +74,20 @@ func (c *RedisCache) Has(str string) (bool, error) {
 	return exists, nil
 }
 
+// namespace returns the key namespace for the cache, which is the Prefix
+// wrapped in a cluster hash tag when HashTag is enabled.
+func (c *RedisCache) namespace() string {
+	if c.HashTag {
+		return "{" + c.Prefix + "}"
+	}
+	return c.Prefix
+}
+
+// key returns the fully qualified Redis key for str (e.g., "app1:user" or "{app1}:user").
+func (c *RedisCache) key(str string) string {
+	return fmt.Sprintf("%s:%s", c.namespace(), str)
+}
+
 // encode serializes an Entry into a byte slice using gob encoding.
 //
 // It returns the encoded bytes and an error if encoding fails.
@@ -104,7 +125,7 @@ func decode(data []byte) (Entry, error) {
 
 // Get retrieves a value from the Redis cache by key.
 //
-// The key is prefixed with the RedisCache Prefix (e.g., "prefix:key").
+// The key is prefixed with the RedisCache namespace (e.g., "prefix:key").
 // It returns the cached value as an interface{} and an error if retrieval or decoding fails.
 // Returns nil, nil if the key does not exist.
 //
@@ -115,7 +136,7 @@ func decode(data []byte) (Entry, error) {
 //	if err != nil { /* handle error */ }
 //	if value != nil { /* use value */ }
 func (c *RedisCache) Get(str string) (interface{}, error) {
-	key := fmt.Sprintf("%s:%s", c.Prefix, str)
+	key := c.key(str)
 	conn := c.Conn.Get()
 	defer func() {
 		if err := conn.Close(); err != nil {
@@ -145,7 +166,7 @@ func (c *RedisCache) Get(str string) (interface{}, error) {
 
 // Set stores a value in the Redis cache with an optional expiration time.
 //
-// The key is prefixed with the RedisCache Prefix (e.g., "prefix:key").
+// The key is prefixed with the RedisCache namespace (e.g., "prefix:key").
 // The value is stored under the "value" key in an Entry map.
 // The optional expires parameter specifies the TTL in seconds; if omitted, the key persists indefinitely.
 // It returns an error if encoding or storage fails.
@@ -156,7 +177,7 @@ func (c *RedisCache) Get(str string) (interface{}, error) {
 //	err := cache.Set("user", "data", 3600) // Sets "app1:user" with 1-hour TTL
 //	err := cache.Set("session", "token")   // Sets "app1:session" with no expiration
 func (c *RedisCache) Set(str string, value interface{}, expires ...int) error {
-	key := fmt.Sprintf("%s:%s", c.Prefix, str)
+	key := c.key(str)
 	conn := c.Conn.Get()
 	defer func() {
 		if err := conn.Close(); err != nil {
@@ -183,7 +204,7 @@ func (c *RedisCache) Set(str string, value interface{}, expires ...int) error {
 
 // Forget removes a specific key from the Redis cache.
 //
-// The key is prefixed with the RedisCache Prefix (e.g., "prefix:key").
+// The key is prefixed with the RedisCache namespace (e.g., "prefix:key").
 // It returns an error if the deletion fails.
 //
 // Example:
@@ -191,7 +212,7 @@ func (c *RedisCache) Set(str string, value interface{}, expires ...int) error {
 //	cache := &RedisCache{Conn: pool, Prefix: "app1"}
 //	err := cache.Forget("user") // Deletes "app1:user"
 func (c *RedisCache) Forget(str string) error {
-	key := fmt.Sprintf("%s:%s", c.Prefix, str)
+	key := c.key(str)
 	conn := c.Conn.Get()
 	defer func() {
 		if err := conn.Close(); err != nil {
@@ -224,7 +245,7 @@ func (c *RedisCache) EmptyByMatch(pattern string) error {
 		}
 	}()
 
-	matchPattern := fmt.Sprintf("%s:%s", c.Prefix, pattern)
+	matchPattern := c.key(pattern)
 	keys, err := c.getKeys(matchPattern)
 	if err != nil {
 		return fmt.Errorf("failed to get keys for pattern %s: %w", matchPattern, err)
@@ -264,7 +285,7 @@ func (c *RedisCache) Empty() error {
 		}
 	}()
 
-	pattern := fmt.Sprintf("%s:", c.Prefix)
+	pattern := c.namespace() + ":"
 	keys, err := c.getKeys(pattern)
 	if err != nil {
 		return fmt.Errorf("failed to get keys for prefix %s: %w", pattern, err)
@@ -338,3 +359,57 @@ func (c *RedisCache) getKeys(pattern string) ([]string, error) {
 
 	return keys, nil
 }
+
+// ServerStats holds statistics reported by Redis for the keys of a RedisCache.
+type ServerStats struct {
+	Keys   map[string]int // number of keys under the cache namespace, grouped by KeyPrefix
+	Hits   int64          // server-wide keyspace_hits reported by INFO stats
+	Misses int64          // server-wide keyspace_misses reported by INFO stats
+}
+
+// ServerStats counts the keys stored under the RedisCache namespace, grouped by
+// key prefix, and reads the server-wide hit and miss counters from INFO stats.
+// Counters missing from the INFO reply (e.g., on proxies that do not expose them) are left at zero.
+//
+// Example:
+//
+//	cache := &RedisCache{Conn: pool, Prefix: "app1"}
+//	stats, err := cache.ServerStats()
+//	fmt.Println(stats.Keys["user"]) // Number of "app1:user:*" keys
+func (c *RedisCache) ServerStats() (*ServerStats, error) {
+	namespace := c.namespace() + ":"
+	keys, err := c.getKeys(namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get keys for prefix %s: %w", namespace, err)
+	}
+
+	stats := &ServerStats{Keys: make(map[string]int)}
+	for _, key := range keys {
+		stats.Keys[KeyPrefix(strings.TrimPrefix(key, namespace))]++
+	}
+
+	conn := c.Conn.Get()
+	defer func() {
+		if err := conn.Close(); err != nil {
+			log.Printf("Failed to close Redis connection: %v", err)
+		}
+	}()
+
+	info, err := redis.String(conn.Do("INFO", "stats"))
+	if err != nil {
+		return stats, nil
+	}
+	for _, line := range strings.Split(info, "\n") {
+		name, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+		switch name {
+		case "keyspace_hits":
+			stats.Hits, _ = strconv.ParseInt(value, 10, 64)
+		case "keyspace_misses":
+			stats.Misses, _ = strconv.ParseInt(value, 10, 64)
+		}
+	}
+	return stats, nil
+}
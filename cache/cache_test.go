@@ -288,3 +288,37 @@ func TestRedisCache_EmptyByMatch(t *testing.T) {
 		}
 	}
 }
+
+func TestRedisCache_HashTag(t *testing.T) {
+	tagged := RedisCache{
+		Conn:    testRedisCache.Conn,
+		Prefix:  "test-devify",
+		HashTag: true,
+	}
+	if err := tagged.Empty(); err != nil {
+		t.Fatalf("Failed to reset cache: %v", err)
+	}
+
+	if err := tagged.Set("user:1", "data"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	if !testRedisServer.Exists("{test-devify}:user:1") {
+		t.Errorf("expected key {test-devify}:user:1 to exist, keys = %v", testRedisServer.Keys())
+	}
+
+	got, err := tagged.Get("user:1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got != "data" {
+		t.Errorf("Get() = %v, want %v", got, "data")
+	}
+
+	if err := tagged.Empty(); err != nil {
+		t.Errorf("Empty() error = %v", err)
+	}
+	if testRedisServer.Exists("{test-devify}:user:1") {
+		t.Error("key {test-devify}:user:1 still exists after Empty()")
+	}
+}
@@ -0,0 +1,179 @@
+package cache
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultStatsPrefix groups keys that have no ":" separated prefix.
+const defaultStatsPrefix = "default"
+
+// Stats holds the counters recorded for one key prefix by an InstrumentedCache.
+type Stats struct {
+	Hits         uint64        // Get or Has calls that found the key
+	Misses       uint64        // Get or Has calls that did not find the key
+	Errors       uint64        // calls that returned an error
+	Calls        uint64        // total number of calls
+	TotalLatency time.Duration // sum of the latency of all calls
+	MaxLatency   time.Duration // slowest single call
+}
+
+// HitRate returns the ratio of hits to lookups, or 0 if there were no lookups.
+func (s Stats) HitRate() float64 {
+	lookups := s.Hits + s.Misses
+	if lookups == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(lookups)
+}
+
+// AvgLatency returns the mean latency per call, or 0 if there were no calls.
+func (s Stats) AvgLatency() time.Duration {
+	if s.Calls == 0 {
+		return 0
+	}
+	return s.TotalLatency / time.Duration(s.Calls)
+}
+
+// InstrumentedCache decorates a Cache and records hits, misses, errors and latency
+// per key prefix. The prefix of a key is the part before its first ":" (e.g., "user"
+// for "user:42"); keys without a separator are grouped under "default".
+//
+// Example:
+//
+//	d.Cache = cache.NewInstrumentedCache(d.Cache)
+//	stats := d.Cache.(*cache.InstrumentedCache).Stats()
+//	fmt.Println(stats["user"].HitRate())
+type InstrumentedCache struct {
+	Cache Cache // the underlying cache
+
+	mu    sync.Mutex
+	stats map[string]*Stats
+}
+
+// NewInstrumentedCache returns an InstrumentedCache wrapping c.
+func NewInstrumentedCache(c Cache) *InstrumentedCache {
+	return &InstrumentedCache{
+		Cache: c,
+		stats: make(map[string]*Stats),
+	}
+}
+
+// Has checks if a key exists in the underlying cache, counting a hit or a miss.
+func (c *InstrumentedCache) Has(key string) (bool, error) {
+	start := time.Now()
+	exists, err := c.Cache.Has(key)
+	c.record(key, time.Since(start), err, &exists)
+	return exists, err
+}
+
+// Get retrieves a value from the underlying cache, counting a hit or a miss.
+func (c *InstrumentedCache) Get(key string) (interface{}, error) {
+	start := time.Now()
+	value, err := c.Cache.Get(key)
+	found := value != nil
+	c.record(key, time.Since(start), err, &found)
+	return value, err
+}
+
+// Set stores a value in the underlying cache.
+func (c *InstrumentedCache) Set(key string, value interface{}, expires ...int) error {
+	start := time.Now()
+	err := c.Cache.Set(key, value, expires...)
+	c.record(key, time.Since(start), err, nil)
+	return err
+}
+
+// Forget removes a key from the underlying cache.
+func (c *InstrumentedCache) Forget(key string) error {
+	start := time.Now()
+	err := c.Cache.Forget(key)
+	c.record(key, time.Since(start), err, nil)
+	return err
+}
+
+// EmptyByMatch removes all entries matching pattern from the underlying cache.
+func (c *InstrumentedCache) EmptyByMatch(pattern string) error {
+	start := time.Now()
+	err := c.Cache.EmptyByMatch(pattern)
+	c.record(pattern, time.Since(start), err, nil)
+	return err
+}
+
+// Empty removes all entries from the underlying cache.
+func (c *InstrumentedCache) Empty() error {
+	start := time.Now()
+	err := c.Cache.Empty()
+	c.record(defaultStatsPrefix, time.Since(start), err, nil)
+	return err
+}
+
+// Stats returns a snapshot of the counters keyed by key prefix.
+func (c *InstrumentedCache) Stats() map[string]Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	snapshot := make(map[string]Stats, len(c.stats))
+	for prefix, s := range c.stats {
+		snapshot[prefix] = *s
+	}
+	return snapshot
+}
+
+// Reset clears all recorded counters.
+func (c *InstrumentedCache) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats = make(map[string]*Stats)
+}
+
+// record updates the counters for the prefix of key. found is nil for
+// operations that are not lookups.
+func (c *InstrumentedCache) record(key string, latency time.Duration, err error, found *bool) {
+	prefix := KeyPrefix(key)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stats == nil {
+		c.stats = make(map[string]*Stats)
+	}
+	s, ok := c.stats[prefix]
+	if !ok {
+		s = &Stats{}
+		c.stats[prefix] = s
+	}
+
+	s.Calls++
+	s.TotalLatency += latency
+	if latency > s.MaxLatency {
+		s.MaxLatency = latency
+	}
+
+	switch {
+	case err != nil:
+		s.Errors++
+	case found == nil:
+	case *found:
+		s.Hits++
+	default:
+		s.Misses++
+	}
+}
+
+// KeyPrefix returns the part of key before its first ":", which is how both
+// InstrumentedCache and RedisCache.ServerStats group keys. Keys without a
+// separator map to "default". Trailing glob characters are trimmed so that a
+// pattern such as "user*" groups with "user:42".
+func KeyPrefix(key string) string {
+	prefix, _, found := strings.Cut(key, ":")
+	if !found && !strings.ContainsAny(key, "*?[") {
+		return defaultStatsPrefix
+	}
+	prefix = strings.TrimRight(prefix, "*?[")
+	if prefix == "" {
+		return defaultStatsPrefix
+	}
+	return prefix
+}
@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"testing"
+)
+
+func TestInstrumentedCache_Stats(t *testing.T) {
+	if err := resetCache(); err != nil {
+		t.Fatalf("Failed to reset cache: %v", err)
+	}
+
+	ic := NewInstrumentedCache(&testRedisCache)
+
+	if err := ic.Set("user:1", "alice"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if _, err := ic.Get("user:1"); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if _, err := ic.Get("user:2"); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if _, err := ic.Has("other"); err != nil {
+		t.Fatalf("Has() error = %v", err)
+	}
+
+	stats := ic.Stats()
+
+	tests := []struct {
+		prefix string
+		hits   uint64
+		misses uint64
+		calls  uint64
+	}{
+		{"user", 1, 1, 3},
+		{"default", 0, 1, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			s, ok := stats[tt.prefix]
+			if !ok {
+				t.Fatalf("no stats recorded for prefix %s", tt.prefix)
+			}
+			if s.Hits != tt.hits || s.Misses != tt.misses || s.Calls != tt.calls {
+				t.Errorf("Stats()[%s] = %+v, want hits %d misses %d calls %d", tt.prefix, s, tt.hits, tt.misses, tt.calls)
+			}
+			if s.Errors != 0 {
+				t.Errorf("Stats()[%s].Errors = %d, want 0", tt.prefix, s.Errors)
+			}
+		})
+	}
+
+	if got := stats["user"].HitRate(); got != 0.5 {
+		t.Errorf("HitRate() = %v, want 0.5", got)
+	}
+
+	ic.Reset()
+	if len(ic.Stats()) != 0 {
+		t.Error("Stats() not empty after Reset()")
+	}
+}
+
+func TestKeyPrefix(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"user:42", "user"},
+		{"user:42:profile", "user"},
+		{"user*", "user"},
+		{"session", "default"},
+		{":odd", "default"},
+	}
+
+	for _, tt := range tests {
+		if got := KeyPrefix(tt.key); got != tt.want {
+			t.Errorf("KeyPrefix(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestRedisCache_ServerStats(t *testing.T) {
+	if err := resetCache(); err != nil {
+		t.Fatalf("Failed to reset cache: %v", err)
+	}
+
+	for _, key := range []string{"user:1", "user:2", "post:1", "other"} {
+		if err := testRedisCache.Set(key, "data"); err != nil {
+			t.Fatalf("Setup failed: %v", err)
+		}
+	}
+
+	stats, err := testRedisCache.ServerStats()
+	if err != nil {
+		t.Fatalf("ServerStats() error = %v", err)
+	}
+
+	want := map[string]int{"user": 2, "post": 1, "default": 1}
+	for prefix, count := range want {
+		if stats.Keys[prefix] != count {
+			t.Errorf("ServerStats().Keys[%s] = %d, want %d", prefix, stats.Keys[prefix], count)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+
+	"github.com/gomodule/redigo/redis"
+)
+
+// ErrNoSentinel is returned when none of the configured Sentinels could resolve the master.
+var ErrNoSentinel = errors.New("no sentinel could resolve the redis master")
+
+// SentinelMasterAddr discovers the current address of a Redis master through Sentinel.
+//
+// Each Sentinel in sentinels is queried in order with "SENTINEL get-master-addr-by-name"
+// and the first successful answer is returned as "host:port". The options are used to
+// dial the Sentinels themselves (e.g., a Sentinel password or connect timeout), not the master.
+// It returns ErrNoSentinel, wrapping the last failure, if no Sentinel answers.
+//
+// Example:
+//
+//	addr, err := SentinelMasterAddr([]string{"10.0.0.1:26379", "10.0.0.2:26379"}, "mymaster")
+//	conn, err := redis.Dial("tcp", addr)
+func SentinelMasterAddr(sentinels []string, masterName string, options ...redis.DialOption) (string, error) {
+	if len(sentinels) == 0 {
+		return "", fmt.Errorf("%w: no sentinel addresses configured", ErrNoSentinel)
+	}
+
+	var lastErr error
+	for _, sentinel := range sentinels {
+		addr, err := queryMasterAddr(sentinel, masterName, options...)
+		if err != nil {
+			log.Printf("Sentinel %s failed to resolve master %s: %v", sentinel, masterName, err)
+			lastErr = err
+			continue
+		}
+		return addr, nil
+	}
+	return "", fmt.Errorf("%w %s: %v", ErrNoSentinel, masterName, lastErr)
+}
+
+// queryMasterAddr asks a single Sentinel for the address of the named master.
+func queryMasterAddr(sentinel, masterName string, options ...redis.DialOption) (string, error) {
+	conn, err := redis.Dial("tcp", sentinel, options...)
+	if err != nil {
+		return "", fmt.Errorf("failed to dial sentinel %s: %w", sentinel, err)
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			log.Printf("Failed to close Sentinel connection: %v", err)
+		}
+	}()
+
+	res, err := redis.Strings(conn.Do("SENTINEL", "get-master-addr-by-name", masterName))
+	if err == redis.ErrNil {
+		return "", fmt.Errorf("sentinel %s does not know master %s", sentinel, masterName)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to query sentinel %s: %w", sentinel, err)
+	}
+	if len(res) != 2 {
+		return "", fmt.Errorf("unexpected sentinel reply from %s: %v", sentinel, res)
+	}
+	return net.JoinHostPort(res[0], res[1]), nil
+}
+
+// CheckMasterRole verifies that conn is connected to a Redis master.
+//
+// It is intended for use in a redis.Pool TestOnBorrow function so that pooled
+// connections to a node demoted by a Sentinel failover are discarded and redialed.
+func CheckMasterRole(conn redis.Conn) error {
+	reply, err := redis.Values(conn.Do("ROLE"))
+	if err != nil {
+		return fmt.Errorf("failed to check redis role: %w", err)
+	}
+	if len(reply) == 0 {
+		return errors.New("empty reply to ROLE")
+	}
+	role, err := redis.String(reply[0], nil)
+	if err != nil {
+		return fmt.Errorf("failed to parse redis role: %w", err)
+	}
+	if !strings.EqualFold(role, "master") {
+		return fmt.Errorf("redis node has role %s, expected master", role)
+	}
+	return nil
+}
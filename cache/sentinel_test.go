@@ -0,0 +1,119 @@
+package cache
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+)
+
+// startFakeSentinel starts a minimal RESP server that answers
+// "SENTINEL get-master-addr-by-name" for the given masters.
+func startFakeSentinel(t *testing.T, masters map[string]string) string {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to start fake sentinel: %v", err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go serveFakeSentinel(conn, masters)
+		}
+	}()
+
+	return l.Addr().String()
+}
+
+func serveFakeSentinel(conn net.Conn, masters map[string]string) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		var argc int
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		if _, err := fmt.Sscanf(line, "*%d\r\n", &argc); err != nil {
+			return
+		}
+		args := make([]string, argc)
+		for i := range args {
+			if _, err := r.ReadString('\n'); err != nil { // $len
+				return
+			}
+			arg, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			args[i] = strings.TrimSuffix(arg, "\r\n")
+		}
+
+		addr, ok := masters[args[len(args)-1]]
+		if !ok || !strings.EqualFold(args[0], "SENTINEL") {
+			_, _ = conn.Write([]byte("*-1\r\n"))
+			continue
+		}
+		host, port, _ := net.SplitHostPort(addr)
+		_, _ = fmt.Fprintf(conn, "*2\r\n$%d\r\n%s\r\n$%d\r\n%s\r\n", len(host), host, len(port), port)
+	}
+}
+
+func TestSentinelMasterAddr(t *testing.T) {
+	sentinel := startFakeSentinel(t, map[string]string{"mymaster": "10.0.0.5:6380"})
+
+	tests := []struct {
+		name      string
+		sentinels []string
+		master    string
+		want      string
+		wantErr   bool
+	}{
+		{
+			name:      "known master",
+			sentinels: []string{sentinel},
+			master:    "mymaster",
+			want:      "10.0.0.5:6380",
+		},
+		{
+			name:      "falls through unreachable sentinel",
+			sentinels: []string{"127.0.0.1:1", sentinel},
+			master:    "mymaster",
+			want:      "10.0.0.5:6380",
+		},
+		{
+			name:      "unknown master",
+			sentinels: []string{sentinel},
+			master:    "other",
+			wantErr:   true,
+		},
+		{
+			name:    "no sentinels",
+			master:  "mymaster",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SentinelMasterAddr(tt.sentinels, tt.master)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SentinelMasterAddr() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, ErrNoSentinel) {
+				t.Errorf("SentinelMasterAddr() error = %v, want ErrNoSentinel", err)
+			}
+			if got != tt.want {
+				t.Errorf("SentinelMasterAddr() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
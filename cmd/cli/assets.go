@@ -0,0 +1,127 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/jorgeSader/devify/render"
+)
+
+// fingerprintLength is the number of hex characters of the content hash added to asset names.
+const fingerprintLength = 12
+
+// fingerprintedName matches names with a fingerprint, such as app.3f2a9c1d04be.css.
+var fingerprintedName = regexp.MustCompile(`\.[0-9a-f]{12}(\.[^.]*)?$`)
+
+// uploadsDir is the directory of public/ where UploadFile saves files by
+// default; uploads are not assets.
+const uploadsDir = "uploads"
+
+func doAssets(arg2 string) error {
+	if cel.RootPath == "" {
+		return errors.New("RootPath not set; setup failed")
+	}
+
+	switch arg2 {
+	case "build":
+		manifest, err := buildAssets(filepath.Join(cel.RootPath, "public"))
+		if err != nil {
+			return err
+		}
+		color.Yellow("Fingerprinted %d assets into public/%s", len(manifest), render.ManifestFile)
+
+	default:
+		return errors.New("assets requires a subcommand: (build)")
+	}
+
+	return nil
+}
+
+// buildAssets copies every file in publicPath to a name containing a hash of its
+// content (css/app.css -> css/app.3f2a9c1d04be.css) and writes the mapping to
+// the manifest. Files fingerprinted by a previous build are removed first.
+// Dotfiles, the uploads directory and names that already carry a fingerprint
+// are skipped, the latter so that stale copies left without a manifest are
+// not fingerprinted again.
+func buildAssets(publicPath string) (render.Manifest, error) {
+	old, err := render.ReadManifest(os.DirFS(publicPath))
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range old {
+		err := os.Remove(filepath.Join(publicPath, filepath.FromSlash(name)))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	manifest := render.Manifest{}
+	err = fs.WalkDir(os.DirFS(publicPath), ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if strings.HasPrefix(entry.Name(), ".") && name != "." {
+			if entry.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if entry.IsDir() && name == uploadsDir {
+			return fs.SkipDir
+		}
+		if entry.IsDir() || name == render.ManifestFile || fingerprintedName.MatchString(entry.Name()) {
+			return nil
+		}
+
+		fingerprinted, err := fingerprintAsset(publicPath, name)
+		if err != nil {
+			return err
+		}
+		manifest[name] = fingerprinted
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	err = os.WriteFile(filepath.Join(publicPath, render.ManifestFile), append(b, '\n'), 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// fingerprintAsset writes a copy of the asset at name, relative to publicPath,
+// under its fingerprinted name and returns that name.
+func fingerprintAsset(publicPath, name string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(publicPath, filepath.FromSlash(name)))
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])[:fingerprintLength]
+
+	ext := path.Ext(name)
+	fingerprinted := strings.TrimSuffix(name, ext) + "." + hash + ext
+
+	err = os.WriteFile(filepath.Join(publicPath, filepath.FromSlash(fingerprinted)), data, 0644)
+	if err != nil {
+		return "", err
+	}
+	return fingerprinted, nil
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/jorgeSader/devify/render"
+)
+
+// fingerprint returns the name buildAssets gives the asset name with content.
+func fingerprint(name, content string) string {
+	sum := sha256.Sum256([]byte(content))
+	ext := path.Ext(name)
+	return strings.TrimSuffix(name, ext) + "." + hex.EncodeToString(sum[:])[:fingerprintLength] + ext
+}
+
+func TestBuildAssets(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string // files in public/, by slash-separated name
+		want  []string          // assets in the manifest
+		gone  []string          // files removed by the build
+	}{
+		{"fresh build", map[string]string{
+			"css/app.css":      "body{}",
+			"js/app.js":        "console.log(1)",
+			"favicon.ico":      "icon",
+			"robots":           "no extension",
+			"images/logo.png":  "png",
+			".gitignore":       "*",
+			".well-known/x":    "hidden",
+			"css/.keep":        "",
+			"uploads/a1b2.png": "user upload",
+		}, []string{"css/app.css", "favicon.ico", "images/logo.png", "js/app.js", "robots"}, nil},
+		{"previous build removed", map[string]string{
+			"css/app.css":              "body{color:red}",
+			"css/app.000000000000.css": "body{}",
+			render.ManifestFile:        `{"css/app.css": "css/app.000000000000.css"}`,
+		}, []string{"css/app.css"}, []string{"css/app.000000000000.css"}},
+		{"stale copies without a manifest", map[string]string{
+			"css/app.css":              "body{}",
+			"css/app.0123456789ab.css": "body{color:blue}",
+			"js/app.abcdefabcdef":      "old",
+		}, []string{"css/app.css"}, nil},
+		{"uploads nested deeper are assets", map[string]string{
+			"images/uploads/icon.png": "png",
+		}, []string{"images/uploads/icon.png"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			public := t.TempDir()
+			for name, content := range tt.files {
+				file := filepath.Join(public, filepath.FromSlash(name))
+				if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			manifest, err := buildAssets(public)
+			if err != nil {
+				t.Fatalf("buildAssets() error = %v", err)
+			}
+
+			var assets []string
+			for asset := range manifest {
+				assets = append(assets, asset)
+			}
+			sort.Strings(assets)
+			if !reflect.DeepEqual(assets, tt.want) {
+				t.Errorf("manifest assets = %v, want %v", assets, tt.want)
+			}
+
+			for _, asset := range tt.want {
+				want := fingerprint(asset, tt.files[asset])
+				if manifest[asset] != want {
+					t.Errorf("manifest[%s] = %s, want %s", asset, manifest[asset], want)
+				}
+				b, err := os.ReadFile(filepath.Join(public, filepath.FromSlash(want)))
+				if err != nil || string(b) != tt.files[asset] {
+					t.Errorf("fingerprinted %s = %q, %v; want %q", want, b, err, tt.files[asset])
+				}
+			}
+
+			for _, name := range tt.gone {
+				if _, err := os.Stat(filepath.Join(public, filepath.FromSlash(name))); !errors.Is(err, fs.ErrNotExist) {
+					t.Errorf("%s was not removed: %v", name, err)
+				}
+			}
+
+			written, err := render.ReadManifest(os.DirFS(public))
+			if err != nil || !reflect.DeepEqual(written, manifest) {
+				t.Errorf("%s = %v, %v; want %v", render.ManifestFile, written, err, manifest)
+			}
+
+			// A second build replaces the first one's copies with the same names.
+			again, err := buildAssets(public)
+			if err != nil || !reflect.DeepEqual(again, manifest) {
+				t.Errorf("second buildAssets() = %v, %v; want %v", again, err, manifest)
+			}
+		})
+	}
+}
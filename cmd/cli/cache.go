@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/fatih/color"
+)
+
+func doCache(arg2, arg3 string) error {
+	if cel.RootPath == "" {
+		return errors.New("RootPath not set; setup failed")
+	}
+
+	redisCache, err := cel.OpenRedisCache()
+	if err != nil {
+		return err
+	}
+	defer redisCache.Conn.Close()
+
+	switch arg2 {
+	case "clear":
+		if arg3 == "" {
+			err = redisCache.Empty()
+			if err != nil {
+				return err
+			}
+			color.Yellow("Cleared all keys with prefix %s", redisCache.Prefix)
+			return nil
+		}
+
+		err = redisCache.EmptyByMatch(arg3)
+		if err != nil {
+			return err
+		}
+		color.Yellow("Cleared keys matching %s:%s", redisCache.Prefix, arg3)
+
+	case "get":
+		if arg3 == "" {
+			return errors.New("cache get requires a key")
+		}
+
+		value, err := redisCache.Get(arg3)
+		if err != nil {
+			return err
+		}
+		if value == nil {
+			color.Yellow("%s: (not found)", arg3)
+			return nil
+		}
+		color.Yellow("%s: %v", arg3, value)
+
+	case "stats":
+		stats, err := redisCache.ServerStats()
+		if err != nil {
+			return err
+		}
+
+		prefixes := make([]string, 0, len(stats.Keys))
+		total := 0
+		for prefix, count := range stats.Keys {
+			prefixes = append(prefixes, prefix)
+			total += count
+		}
+		sort.Strings(prefixes)
+
+		color.Yellow("Keys with prefix %s: %d", redisCache.Prefix, total)
+		for _, prefix := range prefixes {
+			color.Yellow("  %-30s %d", prefix, stats.Keys[prefix])
+		}
+
+		hitRate := 0.0
+		if lookups := stats.Hits + stats.Misses; lookups > 0 {
+			hitRate = float64(stats.Hits) / float64(lookups) * 100
+		}
+		color.Yellow("Server keyspace hits: %d, misses: %d (%.1f%% hit rate)", stats.Hits, stats.Misses, hitRate)
+
+	default:
+		return errors.New("cache requires a subcommand: (clear|get|stats)")
+	}
+
+	return nil
+}
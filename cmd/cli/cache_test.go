@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/fatih/color"
+	"github.com/gomodule/redigo/redis"
+	"github.com/jorgeSader/devify/cache"
+)
+
+func TestDoCache(t *testing.T) {
+	tests := []struct {
+		name    string
+		arg2    string
+		arg3    string
+		wantErr bool
+		output  []string // lines the command prints
+		keys    []string // keys left in Redis
+	}{
+		{"clear", "clear", "", false, []string{"Cleared all keys with prefix test"},
+			[]string{"other:user:1"}},
+		{"clear by match", "clear", "user*", false, []string{"Cleared keys matching test:user*"},
+			[]string{"other:user:1", "test:page:home"}},
+		{"get", "get", "page:home", false, []string{"page:home: <html>"}, nil},
+		{"get a missing key", "get", "page:about", false, []string{"page:about: (not found)"}, nil},
+		{"get without a key", "get", "", true, nil, nil},
+		{"stats", "stats", "", false, []string{
+			"Keys with prefix test: 3",
+			"page                           1",
+			"user                           2",
+			"Server keyspace hits:",
+		}, nil},
+		{"unknown subcommand", "flush", "", true, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := miniredis.RunT(t)
+			t.Setenv("REDIS_HOST", s.Addr())
+			t.Setenv("REDIS_PREFIX", "test")
+
+			pool := &redis.Pool{Dial: func() (redis.Conn, error) { return redis.Dial("tcp", s.Addr()) }}
+			defer pool.Close()
+			seed := &cache.RedisCache{Conn: pool, Prefix: "test"}
+			for key, value := range map[string]string{"user:1": "ada", "user:2": "eve", "page:home": "<html>"} {
+				if err := seed.Set(key, value); err != nil {
+					t.Fatal(err)
+				}
+			}
+			_ = s.Set("other:user:1", "kept")
+
+			cel.RootPath = t.TempDir()
+			var out bytes.Buffer
+			output, noColor := color.Output, color.NoColor
+			color.Output, color.NoColor = &out, true
+			defer func() { color.Output, color.NoColor = output, noColor }()
+
+			err := doCache(tt.arg2, tt.arg3)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("doCache(%q, %q) error = %v, wantErr %v", tt.arg2, tt.arg3, err, tt.wantErr)
+			}
+			for _, line := range tt.output {
+				if !strings.Contains(out.String(), line) {
+					t.Errorf("output %q does not contain %q", out.String(), line)
+				}
+			}
+			if tt.keys != nil {
+				keys := s.Keys()
+				sort.Strings(keys)
+				if !reflect.DeepEqual(keys, tt.keys) {
+					t.Errorf("keys = %v, want %v", keys, tt.keys)
+				}
+			}
+		})
+	}
+}
@@ -80,6 +80,10 @@ func showHelp() {
 	make handler <name>     - creates a stub handler in the handlers directory
 	make model <name>       - creates a new model in the data directory
 	make session            - creates a table in the database as a session store
+	cache clear [pattern]   - removes all cache keys, or only those matching pattern
+	cache get <key>         - prints the cached value stored under key
+	cache stats             - shows key counts per prefix and the server hit rate
+	assets build            - fingerprints the files in public and writes public/manifest.json
 
 	`)
 }
@@ -46,6 +46,18 @@ func main() {
 			exitGracefully(err)
 		}
 
+	case "cache":
+		err = doCache(arg2, arg3)
+		if err != nil {
+			exitGracefully(err)
+		}
+
+	case "assets":
+		err = doAssets(arg2)
+		if err != nil {
+			exitGracefully(err)
+		}
+
 	default:
 		showHelp()
 	}
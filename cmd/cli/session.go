@@ -4,11 +4,15 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/fatih/color"
 )
 
+var validTableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
 func doSessionTable() error {
 	// Validate cel initialization
 	if cel.DB.DataType == "" {
@@ -21,6 +25,15 @@ func doSessionTable() error {
 	dbType := cel.DB.DataType
 	var templateFile string
 
+	// Sessions live in SESSION_TABLE, which must match what the app passes to the session store
+	tableName := os.Getenv("SESSION_TABLE")
+	if tableName == "" {
+		tableName = "sessions"
+	}
+	if !validTableName.MatchString(tableName) {
+		return fmt.Errorf("invalid SESSION_TABLE %q: use letters, digits and underscores only", tableName)
+	}
+
 	switch dbType {
 	case "mysql", "mariadb":
 		templateFile = "mysql_session"
@@ -33,16 +46,14 @@ func doSessionTable() error {
 
 	case "mongo", "mongodb":
 		color.Yellow(`Note: MongoDB doesn't require SQL migrations.
-To setup a MongoDB session store:
-	1. Initialize a new session manager
-	2. Configure it to use mongodbstore
+To use the MongoDB session store, add the following to your .env:
 
-Example:
-	sessionManager = scs.New()
-	sessionManager.Store = mongodbstore.New(client.Database("database"))
+	SESSION_TYPE=mongodb
+	MONGO_URI=mongodb://localhost:27017
+	MONGO_DATABASE=%s
 
-See full example: https://github.com/alexedwards/scs/tree/master/mongodbstore
-`)
+Sessions will be stored in the "%s" collection (set with SESSION_TABLE).
+`, os.Getenv("DATABASE_NAME"), tableName)
 		return nil // No SQL migrations needed for MongoDB
 
 	default:
@@ -50,7 +61,7 @@ See full example: https://github.com/alexedwards/scs/tree/master/mongodbstore
 	}
 
 	// Generate migration file names
-	fileName := fmt.Sprintf("%d_create_sessions_table", time.Now().UnixNano())
+	fileName := fmt.Sprintf("%d_create_%s_table", time.Now().UnixNano(), tableName)
 	migrationDir := cel.RootPath + "/migrations"
 	upFile := migrationDir + "/" + fileName + ".up.sql"
 	downFile := migrationDir + "/" + fileName + ".down.sql"
@@ -60,15 +71,20 @@ See full example: https://github.com/alexedwards/scs/tree/master/mongodbstore
 		return fmt.Errorf("failed to create migrations directory: %v", err)
 	}
 
-	// Copy up migration from embedded templates
+	// Write up migration from embedded templates
 	templatePath := fmt.Sprintf("templates/migrations/%s.sql", templateFile)
-	err := copyFileFromTemplate(templatePath, upFile)
+	data, err := templateFS.ReadFile(templatePath)
+	if err != nil {
+		return fmt.Errorf("failed to read migration template %s: %v", templatePath, err)
+	}
+	migration := strings.ReplaceAll(string(data), "$TABLENAME$", tableName)
+	err = copyDataToFile([]byte(migration), upFile)
 	if err != nil {
-		return fmt.Errorf("failed to copy up migration from %s to %s: %v", templatePath, upFile, err)
+		return fmt.Errorf("failed to write up migration to %s: %v", upFile, err)
 	}
 
 	// Write down migration
-	err = copyDataToFile([]byte("DROP TABLE sessions"), downFile)
+	err = copyDataToFile([]byte("DROP TABLE "+tableName), downFile)
 	if err != nil {
 		return fmt.Errorf("failed to write down migration to %s: %v", downFile, err)
 	}
@@ -1,7 +1,7 @@
-CREATE TABLE sessions (
+CREATE TABLE $TABLENAME$ (
       token CHAR(43) PRIMARY KEY,
       data BLOB NOT NULL,
       expiry TIMESTAMP(6) NOT NULL
 );
 
-CREATE INDEX sessions_expiry_idx ON sessions (expiry);
+CREATE INDEX $TABLENAME$_expiry_idx ON $TABLENAME$ (expiry);
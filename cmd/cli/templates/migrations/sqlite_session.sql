@@ -1,7 +1,7 @@
-CREATE TABLE sessions (
+CREATE TABLE $TABLENAME$ (
       token TEXT PRIMARY KEY,
       data BLOB NOT NULL,
       expiry REAL NOT NULL
 );
 
-CREATE INDEX sessions_expiry_idx ON sessions(expiry);
+CREATE INDEX $TABLENAME$_expiry_idx ON $TABLENAME$(expiry);
@@ -0,0 +1,142 @@
+package devify
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"html/template"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/jorgeSader/devify/render"
+)
+
+const (
+	// CSRFField is the form field checked by the CSRF middleware; render it with {{ csrfField .CSRFToken }}.
+	CSRFField = "csrf_token"
+	// CSRFHeader is the header checked by the CSRF middleware, for JavaScript clients.
+	CSRFHeader = "X-CSRF-Token"
+)
+
+// CSRFExempt excludes requests whose path matches one of patterns from CSRF
+// checks. Patterns use path.Match syntax; a trailing "/*" matches everything
+// below a prefix (e.g., "/webhooks/*"). Call it while setting up routes.
+//
+// Requests authenticated with an Authorization: Bearer header are always
+// exempt, because browsers never attach that header on their own.
+func (d *Devify) CSRFExempt(patterns ...string) {
+	d.csrfExempt = append(d.csrfExempt, patterns...)
+}
+
+// CSRF protects unsafe requests (anything but GET, HEAD, OPTIONS and TRACE)
+// against cross-site request forgery with a synchronizer token kept in the
+// session. The token is exposed to templates as .CSRFToken, created the first
+// time a page renders it (or by CSRFToken), and must be sent back in the
+// X-CSRF-Token header or the csrf_token form field. It is in the default
+// router unless CSRF_PROTECTION is false.
+//
+// The header is checked first. Only without it is the form parsed, limited
+// to MAX_UPLOAD_SIZE for multipart forms and MAX_BODY_SIZE otherwise; larger
+// bodies get 413 Request Entity Too Large. Rejected requests get 403
+// Forbidden, as JSON when the client sent or accepts JSON and as HTML
+// otherwise.
+//
+// Pages with forms must not be shared through CacheResponse, since the cached
+// HTML would carry another session's token.
+func (d *Devify) CSRF(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if d.csrfExempted(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		sent := r.Header.Get(CSRFHeader)
+		if sent == "" {
+			var err error
+			if sent, err = d.csrfFormToken(w, r); err != nil {
+				d.WriteError(w, r, err)
+				return
+			}
+		}
+
+		token := d.Session.GetString(r.Context(), render.CSRFTokenKey)
+		if sent == "" || token == "" || subtle.ConstantTimeCompare([]byte(sent), []byte(token)) != 1 {
+			d.csrfFailure(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// CSRFToken returns the CSRF token of the session in r, creating it if needed.
+func (d *Devify) CSRFToken(r *http.Request) string {
+	ctx := r.Context()
+	token := d.Session.GetString(ctx, render.CSRFTokenKey)
+	if token == "" {
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			return ""
+		}
+		token = base64.RawURLEncoding.EncodeToString(b)
+		d.Session.Put(ctx, render.CSRFTokenKey, token)
+	}
+	return token
+}
+
+// csrfFormToken returns the csrf_token form field of r. Only an oversized body
+// is an error; a malformed form just has no token.
+func (d *Devify) csrfFormToken(w http.ResponseWriter, r *http.Request) (string, error) {
+	d.limitBody(w, r)
+
+	var err error
+	if isMultipart(r) {
+		err = r.ParseMultipartForm(d.maxUploadSize())
+	} else {
+		err = r.ParseForm()
+	}
+
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return "", bodyError(err)
+	}
+	return r.PostForm.Get(CSRFField), nil
+}
+
+// csrfExempted reports whether r needs no CSRF check.
+func (d *Devify) csrfExempted(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+
+	if scheme, _, ok := strings.Cut(r.Header.Get("Authorization"), " "); ok && strings.EqualFold(scheme, "Bearer") {
+		return true
+	}
+
+	for _, pattern := range d.csrfExempt {
+		if prefix, ok := strings.CutSuffix(pattern, "/*"); ok && strings.HasPrefix(r.URL.Path, prefix+"/") {
+			return true
+		}
+		if matched, _ := path.Match(pattern, r.URL.Path); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *Devify) csrfFailure(w http.ResponseWriter, r *http.Request) {
+	const message = "invalid or missing CSRF token"
+
+	if wantsJSON(r) {
+		_ = d.WriteJSON(w, http.StatusForbidden, map[string]string{"error": message})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusForbidden)
+	_, _ = w.Write([]byte("<!DOCTYPE html><title>Forbidden</title><h1>Forbidden</h1><p>" +
+		template.HTMLEscapeString(message) + ". Reload the page and try again.</p>"))
+}
@@ -0,0 +1,125 @@
+package devify
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// csrfSession starts a session holding a CSRF token and returns its cookie and the token.
+func csrfSession(t *testing.T, d *Devify) (*http.Cookie, string) {
+	t.Helper()
+	d.Routes.Get("/token", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, d.CSRFToken(r))
+	})
+	w := serve(d, httptest.NewRequest(http.MethodGet, "/token", nil))
+	return sessionCookie(t, w), w.Body.String()
+}
+
+// multipartBody returns a multipart form with the given fields and a file of size bytes.
+func multipartBody(t *testing.T, fields map[string]string, size int) (io.Reader, string) {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for name, value := range fields {
+		_ = mw.WriteField(name, value)
+	}
+	if size > 0 {
+		part, err := mw.CreateFormFile("file", "data.bin")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = part.Write(bytes.Repeat([]byte("x"), size))
+	}
+	if err := mw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &body, mw.FormDataContentType()
+}
+
+func TestCSRF(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		header func(token string) http.Header
+		body   func(t *testing.T, token string) (io.Reader, string)
+		status int
+	}{
+		{"no token", "/submit", nil, nil, http.StatusForbidden},
+		{"header token", "/submit", func(token string) http.Header {
+			return http.Header{CSRFHeader: {token}}
+		}, nil, http.StatusOK},
+		{"wrong header token", "/submit", func(string) http.Header {
+			return http.Header{CSRFHeader: {"forged"}}
+		}, nil, http.StatusForbidden},
+		{"form token", "/submit", nil, func(t *testing.T, token string) (io.Reader, string) {
+			return strings.NewReader(url.Values{CSRFField: {token}}.Encode()), "application/x-www-form-urlencoded"
+		}, http.StatusOK},
+		{"multipart token", "/submit", nil, func(t *testing.T, token string) (io.Reader, string) {
+			return multipartBody(t, map[string]string{CSRFField: token}, 10)
+		}, http.StatusOK},
+		{"oversized form", "/submit", nil, func(t *testing.T, token string) (io.Reader, string) {
+			return strings.NewReader(CSRFField + "=" + token + "&pad=" + strings.Repeat("x", 2048)), "application/x-www-form-urlencoded"
+		}, http.StatusRequestEntityTooLarge},
+		{"oversized multipart", "/submit", nil, func(t *testing.T, token string) (io.Reader, string) {
+			return multipartBody(t, map[string]string{CSRFField: token}, 8192)
+		}, http.StatusRequestEntityTooLarge},
+		{"oversized body with header token", "/submit", func(token string) http.Header {
+			return http.Header{CSRFHeader: {token}}
+		}, func(t *testing.T, token string) (io.Reader, string) {
+			return strings.NewReader(strings.Repeat("x", 2048)), "application/x-www-form-urlencoded"
+		}, http.StatusOK},
+		{"bearer token", "/submit", func(string) http.Header {
+			return http.Header{"Authorization": {"Bearer abc"}}
+		}, nil, http.StatusOK},
+		{"exempt path", "/webhooks/stripe", nil, nil, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newTestApp(t)
+			d.config.maxBodySize = 1024
+			d.config.maxUploadSize = 4096
+			d.CSRFExempt("/webhooks/*")
+			ok := func(w http.ResponseWriter, r *http.Request) {}
+			d.Routes.Post("/submit", ok)
+			d.Routes.Post("/webhooks/stripe", ok)
+			cookie, token := csrfSession(t, d)
+
+			var body io.Reader
+			contentType := ""
+			if tt.body != nil {
+				body, contentType = tt.body(t, token)
+			}
+			r := httptest.NewRequest(http.MethodPost, tt.path, body)
+			r.AddCookie(cookie)
+			if contentType != "" {
+				r.Header.Set("Content-Type", contentType)
+			}
+			if tt.header != nil {
+				for k, v := range tt.header(token) {
+					r.Header.Set(k, v[0])
+				}
+			}
+
+			if w := serve(d, r); w.Code != tt.status {
+				t.Errorf("status = %d; want %d (%s)", w.Code, tt.status, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestCSRF_NoTokenForSafeRequests(t *testing.T) {
+	d := newTestApp(t)
+	d.Routes.Get("/", func(w http.ResponseWriter, r *http.Request) {})
+
+	w := serve(d, httptest.NewRequest(http.MethodGet, "/", nil))
+	if cookies := w.Result().Cookies(); len(cookies) > 0 {
+		t.Errorf("GET set cookies %v; want none", cookies)
+	}
+}
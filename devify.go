@@ -1,7 +1,12 @@
 package devify
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log"
 	"net/http"
 	"os"
@@ -14,8 +19,12 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/gomodule/redigo/redis"
 	"github.com/jorgeSader/devify/cache"
+	"github.com/jorgeSader/devify/filesystem"
+	"github.com/jorgeSader/devify/i18n"
 	"github.com/jorgeSader/devify/render"
 	"github.com/jorgeSader/devify/session"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/joho/godotenv"
 )
@@ -24,30 +33,43 @@ const version = "1.0.0"
 
 // Devify is the main application struct that holds configuration and logging.
 type Devify struct {
-	AppName       string
-	Debug         bool
-	Version       string
-	ErrorLog      *log.Logger
-	InfoLog       *log.Logger
-	RootPath      string
-	Routes        *chi.Mux
-	Render        *render.Render
-	Session       *scs.SessionManager
-	DB            Database
-	JetViews      *jet.Set
-	config        config
-	EncryptionKey string
-	Cache         cache.Cache
+	AppName        string
+	Debug          bool
+	Version        string
+	ErrorLog       *log.Logger
+	InfoLog        *log.Logger
+	RootPath       string
+	Routes         *chi.Mux
+	Render         *render.Render
+	Session        *scs.SessionManager
+	DB             Database
+	JetViews       *jet.Set
+	config         config
+	EncryptionKey  string
+	Cache          cache.Cache
+	Mongo          *mongo.Client
+	FS             fs.FS // embedded views/, public/ and lang/, used instead of RootPath unless Debug; set before New
+	I18n           *i18n.Translator
+	Uploads        UploadStore        // where UploadFile saves files, e.g. Storage; public/uploads when nil
+	Storage        filesystem.Storage // file storage configured by STORAGE; set before New to use your own
+	redisPool      *redis.Pool
+	csrfExempt     []string
+	panicReporters []PanicReporter
 }
 
 // config holds internal configuration settings for the application.
 type config struct {
-	port        string
-	renderer    string
-	cookie      cookieConfig
-	sessionType string
-	database    databaseConfig
-	redis       redisConfig
+	port          string
+	renderer      string
+	cookie        cookieConfig
+	sessionType   string
+	session       sessionConfig
+	database      databaseConfig
+	redis         redisConfig
+	mongo         mongoConfig
+	locale        localeConfig
+	maxBodySize   int64
+	maxUploadSize int64
 }
 
 // New initializes a new Devify instance with the given root path.
@@ -95,11 +117,6 @@ func (d *Devify) New(rootPath string) error {
 
 	}
 
-	if strings.ToLower(os.Getenv("CACHE")) == "redis" {
-		myRedisCache := d.createClientRedisCache()
-		d.Cache = myRedisCache
-	}
-
 	d.InfoLog = infoLog
 	d.ErrorLog = errorLog
 	d.Debug, err = strconv.ParseBool(os.Getenv("DEBUG"))
@@ -114,43 +131,122 @@ func (d *Devify) New(rootPath string) error {
 		port:     os.Getenv("PORT"),
 		renderer: os.Getenv("RENDERER"),
 		cookie: cookieConfig{
-			name:     os.Getenv("COOKIE_NAME"),
-			lifeTime: os.Getenv("COOKIE_LIFETIME"),
-			persist:  os.Getenv("COOKIE_PERSIST"),
-			secure:   os.Getenv("COOKIE_SECURE"),
-			domain:   os.Getenv("COOKIE_DOMAIN"),
+			name:        os.Getenv("COOKIE_NAME"),
+			lifeTime:    os.Getenv("COOKIE_LIFETIME"),
+			persist:     os.Getenv("COOKIE_PERSIST"),
+			secure:      os.Getenv("COOKIE_SECURE"),
+			httpOnly:    os.Getenv("COOKIE_HTTP_ONLY"),
+			sameSite:    os.Getenv("COOKIE_SAME_SITE"),
+			domain:      os.Getenv("COOKIE_DOMAIN"),
+			idleTimeout: os.Getenv("SESSION_IDLE_TIMEOUT"),
 		},
 		sessionType: os.Getenv("SESSION_TYPE"),
+		session: sessionConfig{
+			table:           os.Getenv("SESSION_TABLE"),
+			cleanupInterval: os.Getenv("SESSION_CLEANUP_INTERVAL"),
+		},
 
 		database: databaseConfig{
 			database: dbType,
 			dsn:      d.BuildDSN(),
 		},
-		redis: redisConfig{
-			host:     os.Getenv("REDIS_HOST"),
-			password: os.Getenv("REDIS_PASSWORD"),
-			prefix:   os.Getenv("REDIS_PREFIX"),
+		redis: redisConfigFromEnv(),
+		mongo: mongoConfig{
+			uri:      os.Getenv("MONGO_URI"),
+			database: os.Getenv("MONGO_DATABASE"),
+		},
+		locale: localeConfig{
+			defaultLocale: os.Getenv("DEFAULT_LOCALE"),
+			urlPrefix:     envBool("LOCALE_URL_PREFIX", false),
 		},
+		maxBodySize:   int64(envInt("MAX_BODY_SIZE", defaultMaxBodySize)),
+		maxUploadSize: int64(envInt("MAX_UPLOAD_SIZE", defaultMaxUploadSize)),
+	}
+
+	if d.config.locale.defaultLocale == "" {
+		d.config.locale.defaultLocale = "en"
+	}
+	langFS := d.embeddedFS()
+	if langFS == nil {
+		langFS = os.DirFS(rootPath)
+	}
+	d.I18n, err = i18n.Load(langFS, "lang", d.config.locale.defaultLocale)
+	if err != nil {
+		return err
+	}
+
+	// The Redis pool is shared by the cache and the session store.
+	if strings.ToLower(os.Getenv("CACHE")) == "redis" || strings.ToLower(d.config.sessionType) == "redis" {
+		d.redisPool, err = d.createRedisPool()
+		if err != nil {
+			return err
+		}
+	}
+
+	if strings.ToLower(os.Getenv("CACHE")) == "redis" {
+		d.Cache = d.createClientRedisCache()
+	}
+
+	var mongoDB *mongo.Database
+	switch strings.ToLower(d.config.sessionType) {
+	case "mongo", "mongodb":
+		d.Mongo, err = d.OpenMongo()
+		if err != nil {
+			return err
+		}
+		database := d.config.mongo.database
+		if database == "" {
+			database = os.Getenv("DATABASE_NAME")
+		}
+		mongoDB = d.Mongo.Database(database)
+	}
+
+	// Record hit rate and latency per key prefix when cache metrics are enabled.
+	if d.Cache != nil && envBool("CACHE_METRICS", false) {
+		d.Cache = cache.NewInstrumentedCache(d.Cache)
+	}
+
+	d.EncryptionKey = os.Getenv("ENCRYPTION_KEY")
+	if strings.ToLower(d.config.sessionType) == "cookie" && d.EncryptionKey == "" {
+		return errors.New("SESSION_TYPE=cookie requires ENCRYPTION_KEY to be set")
+	}
+
+	if err := d.openStorage(); err != nil {
+		return fmt.Errorf("failed to open storage: %w", err)
 	}
 
 	// create session
 	sess := session.Session{
-		CookieName:     d.config.cookie.name,
-		CookieLifetime: d.config.cookie.lifeTime,
-		CookiePersist:  d.config.cookie.persist,
-		CookieSecure:   d.config.cookie.secure,
-		CookieDomain:   d.config.cookie.domain,
-		SessionType:    d.config.sessionType,
-		BDPool:         d.DB.Pool,
+		CookieName:      d.config.cookie.name,
+		CookieLifetime:  d.config.cookie.lifeTime,
+		CookiePersist:   d.config.cookie.persist,
+		CookieSecure:    d.config.cookie.secure,
+		CookieHttpOnly:  d.config.cookie.httpOnly,
+		CookieSameSite:  d.config.cookie.sameSite,
+		CookieDomain:    d.config.cookie.domain,
+		IdleTimeout:     d.config.cookie.idleTimeout,
+		SessionType:     d.config.sessionType,
+		TableName:       d.config.session.table,
+		CleanupInterval: d.config.session.cleanupInterval,
+		BDPool:          d.DB.Pool,
+		MongoDatabase:   mongoDB,
+		RedisPool:       d.redisPool,
+		RedisPrefix:     d.config.redis.sessionPrefix(),
+		EncryptionKeys:  d.encryptionKeys(),
 	}
 
 	d.Session = sess.InitSession()
-	d.EncryptionKey = os.Getenv("ENCRYPTION_KEY")
 
-	var views = jet.NewSet(
-		jet.NewOSFileSystemLoader(fmt.Sprintf("%s/views", rootPath)),
-		jet.InDevelopmentMode(),
-	)
+	// Embedded views can't change, so Jet only reparses templates when loading from disk.
+	var views *jet.Set
+	if fsys := d.embeddedFS(); fsys != nil {
+		views = jet.NewSet(render.NewJetLoader(fsys, "views"))
+	} else {
+		views = jet.NewSet(
+			jet.NewOSFileSystemLoader(fmt.Sprintf("%s/views", rootPath)),
+			jet.InDevelopmentMode(),
+		)
+	}
 
 	d.JetViews = views
 
@@ -184,6 +280,13 @@ func (d *Devify) ListenAndServe() {
 	}
 
 	defer d.DB.Pool.Close()
+	if d.Mongo != nil {
+		defer d.Mongo.Disconnect(context.Background())
+	}
+	if d.redisPool != nil {
+		defer d.redisPool.Close()
+	}
+	defer d.closeStorage()
 
 	d.InfoLog.Printf("Server listening on port %s", d.config.port)
 	err := srv.ListenAndServe()
@@ -213,13 +316,27 @@ func (d *Devify) startLoggers() (*log.Logger, *log.Logger, error) {
 
 func (d *Devify) createRenderer() {
 	myRenderer := render.Render{
-		RootPath: d.RootPath,
-		Renderer: d.config.renderer,
-		Port:     d.config.port,
-		JetViews: d.JetViews,
-		Session:  d.Session,
-		UseCache: false, // TODO: Enable caching by default and/or add to config file
+		RootPath:   d.RootPath,
+		Renderer:   d.config.renderer,
+		Port:       d.config.port,
+		JetViews:   d.JetViews,
+		Session:    d.Session,
+		FS:         d.embeddedFS(),
+		Translator: d.I18n,
+		// Cached Go templates are rebuilt by the views watcher in debug mode,
+		// so caching is on unless TEMPLATE_CACHE=false.
+		UseCache:   envBool("TEMPLATE_CACHE", true),
+		LiveReload: d.Debug && envBool("LIVE_RELOAD", true),
+	}
+	if envBool("CSRF_PROTECTION", true) {
+		myRenderer.CSRFToken = d.CSRFToken
 	}
+	manifest, err := render.ReadManifest(d.PublicFS())
+	if err != nil {
+		d.ErrorLog.Printf("Failed to read asset manifest: %v", err)
+	}
+	myRenderer.Manifest = manifest
+	myRenderer.RegisterJetGlobals()
 
 	// Initialize template cache for Go templates
 	if strings.ToLower(d.config.renderer) == "go" {
@@ -231,31 +348,201 @@ func (d *Devify) createRenderer() {
 	}
 
 	d.Render = &myRenderer
+
+	if d.Debug {
+		if err := d.Render.Watch(context.Background()); err != nil {
+			d.ErrorLog.Printf("Failed to watch views for changes: %v", err)
+		}
+		if d.Render.LiveReload {
+			d.Routes.Get(render.LiveReloadPath, d.Render.LiveReloadHandler)
+		}
+	}
+}
+
+// encryptionKeys returns ENCRYPTION_KEY followed by the retired keys listed in
+// ENCRYPTION_KEY_PREVIOUS (comma separated), which are still accepted for
+// decryption while sessions sealed with them expire.
+func (d *Devify) encryptionKeys() []string {
+	keys := []string{d.EncryptionKey}
+	for _, key := range strings.Split(os.Getenv("ENCRYPTION_KEY_PREVIOUS"), ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// OpenMongo connects to the MongoDB server at MONGO_URI (defaults to
+// mongodb://localhost:27017) and verifies the connection with a ping.
+func (d *Devify) OpenMongo() (*mongo.Client, error) {
+	uri := d.config.mongo.uri
+	if uri == "" {
+		uri = "mongodb://localhost:27017"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to mongodb: %w", err)
+	}
+
+	err = client.Ping(ctx, nil)
+	if err != nil {
+		_ = client.Disconnect(context.Background())
+		return nil, fmt.Errorf("failed to ping mongodb: %w", err)
+	}
+
+	return client, nil
+}
+
+// OpenRedisCache creates a RedisCache from the REDIS_* environment variables.
+// It is intended for tools such as the devify CLI that load the application's
+// .env without calling New.
+func (d *Devify) OpenRedisCache() (*cache.RedisCache, error) {
+	d.config.redis = redisConfigFromEnv()
+	pool, err := d.createRedisPool()
+	if err != nil {
+		return nil, err
+	}
+	d.redisPool = pool
+	return d.createClientRedisCache(), nil
 }
 
 func (d *Devify) createClientRedisCache() *cache.RedisCache {
 	cacheClient := cache.RedisCache{
-		Conn:   d.createRedisPool(),
-		Prefix: d.config.redis.prefix,
+		Conn:    d.redisPool,
+		Prefix:  d.config.redis.prefix,
+		HashTag: d.config.redis.cluster,
 	}
 	return &cacheClient
 }
 
-func (d *Devify) createRedisPool() *redis.Pool {
+// createRedisPool builds a Redis connection pool from the redis config.
+// When a Sentinel master name is configured, every new connection is dialed to
+// the master currently reported by Sentinel, and borrowed connections are checked
+// to still point at a master so that a failover is picked up automatically.
+func (d *Devify) createRedisPool() (*redis.Pool, error) {
+	rc := d.config.redis
+
+	options, err := rc.dialOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	useSentinel := rc.sentinelMaster != ""
+	sentinelOptions := []redis.DialOption{
+		redis.DialConnectTimeout(rc.dialTimeout),
+		redis.DialPassword(rc.sentinelPassword),
+	}
+
 	return &redis.Pool{
-		MaxIdle:     50,
-		MaxActive:   10000,
-		IdleTimeout: 240 * time.Second,
+		MaxIdle:     rc.maxIdle,
+		MaxActive:   rc.maxActive,
+		IdleTimeout: rc.idleTimeout,
 		Dial: func() (redis.Conn, error) {
-			return redis.Dial("tcp",
-				d.config.redis.host,
-				redis.DialPassword(d.config.redis.password))
+			addr := rc.host
+			if useSentinel {
+				masterAddr, err := cache.SentinelMasterAddr(rc.sentinelAddrs, rc.sentinelMaster, sentinelOptions...)
+				if err != nil {
+					return nil, err
+				}
+				addr = masterAddr
+			}
+			return redis.Dial("tcp", addr, options...)
 		},
 		TestOnBorrow: func(c redis.Conn, t time.Time) error {
+			if useSentinel {
+				return cache.CheckMasterRole(c)
+			}
 			_, err := c.Do("PING")
 			return err
 		},
+	}, nil
+}
+
+// redisConfigFromEnv reads the Redis settings from the environment, applying
+// defaults for the pool sizes and timeouts.
+func redisConfigFromEnv() redisConfig {
+	var sentinelAddrs []string
+	for _, addr := range strings.Split(os.Getenv("REDIS_SENTINEL_ADDRS"), ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			sentinelAddrs = append(sentinelAddrs, addr)
+		}
+	}
+
+	return redisConfig{
+		host:             os.Getenv("REDIS_HOST"),
+		username:         os.Getenv("REDIS_USERNAME"),
+		password:         os.Getenv("REDIS_PASSWORD"),
+		prefix:           os.Getenv("REDIS_PREFIX"),
+		database:         envInt("REDIS_DB", 0),
+		cluster:          envBool("REDIS_CLUSTER", false),
+		useTLS:           envBool("REDIS_TLS", false),
+		tlsCAFile:        os.Getenv("REDIS_TLS_CA_FILE"),
+		tlsSkipVerify:    envBool("REDIS_TLS_SKIP_VERIFY", false),
+		dialTimeout:      envDuration("REDIS_DIAL_TIMEOUT", 5*time.Second),
+		readTimeout:      envDuration("REDIS_READ_TIMEOUT", 0),
+		writeTimeout:     envDuration("REDIS_WRITE_TIMEOUT", 0),
+		maxIdle:          envInt("REDIS_MAX_IDLE", 50),
+		maxActive:        envInt("REDIS_MAX_ACTIVE", 10000),
+		idleTimeout:      envDuration("REDIS_IDLE_TIMEOUT", 240*time.Second),
+		sentinelAddrs:    sentinelAddrs,
+		sentinelMaster:   os.Getenv("REDIS_SENTINEL_MASTER"),
+		sentinelPassword: os.Getenv("REDIS_SENTINEL_PASSWORD"),
+	}
+}
+
+// sessionPrefix returns the key prefix for the Redis session store. Sessions are
+// stored under "<REDIS_PREFIX>-session:" (hash-tagged when REDIS_CLUSTER is set,
+// like cache.RedisCache keys) so they share the application's namespace and slot
+// but are not removed when the cache is emptied. It returns "" when REDIS_PREFIX
+// is unset, leaving the session store on its default prefix.
+func (rc redisConfig) sessionPrefix() string {
+	if rc.prefix == "" {
+		return ""
+	}
+	if rc.cluster {
+		return "{" + rc.prefix + "}-session:"
+	}
+	return rc.prefix + "-session:"
+}
+
+// dialOptions converts the redis config into redigo dial options, loading the
+// CA bundle from tlsCAFile when TLS is enabled.
+func (rc redisConfig) dialOptions() ([]redis.DialOption, error) {
+	options := []redis.DialOption{
+		redis.DialUsername(rc.username),
+		redis.DialPassword(rc.password),
+		redis.DialDatabase(rc.database),
+		redis.DialConnectTimeout(rc.dialTimeout),
+		redis.DialReadTimeout(rc.readTimeout),
+		redis.DialWriteTimeout(rc.writeTimeout),
+	}
+
+	if !rc.useTLS {
+		return options, nil
 	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: rc.tlsSkipVerify,
+	}
+
+	if rc.tlsCAFile != "" {
+		pem, err := os.ReadFile(rc.tlsCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read REDIS_TLS_CA_FILE %s: %w", rc.tlsCAFile, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no valid certificates found in REDIS_TLS_CA_FILE %s", rc.tlsCAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return append(options, redis.DialUseTLS(true), redis.DialTLSConfig(tlsConfig)), nil
 }
 
 func (d *Devify) BuildDSN() string {
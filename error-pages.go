@@ -0,0 +1,193 @@
+package devify
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"net/http"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jorgeSader/devify/render"
+)
+
+// PanicReporter is called with every panic recovered by Recoverer, e.g., to
+// forward it to an error tracker. stack is the goroutine stack at the panic.
+type PanicReporter func(r *http.Request, recovered interface{}, stack []byte)
+
+// OnPanic registers fn to be called by Recoverer for every recovered panic.
+// Register reporters while setting up the application.
+//
+// Example:
+//
+//	d.OnPanic(func(r *http.Request, recovered interface{}, stack []byte) {
+//	    sentry.CurrentHub().Recover(recovered)
+//	})
+func (d *Devify) OnPanic(fn PanicReporter) {
+	d.panicReporters = append(d.panicReporters, fn)
+}
+
+// Recoverer recovers from panics in later handlers, logs them with their stack,
+// calls the OnPanic reporters and responds with a 500 error page. It replaces
+// chi's middleware.Recoverer in the default router.
+func (d *Devify) Recoverer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				// Let net/http abort the response as it was asked to.
+				panic(recovered)
+			}
+
+			stack := debug.Stack()
+			if d.ErrorLog != nil {
+				d.ErrorLog.Printf("panic: %v\n%s", recovered, stack)
+			}
+			for _, report := range d.panicReporters {
+				report(r, recovered, stack)
+			}
+
+			if r.Header.Get("Connection") != "Upgrade" {
+				d.serverError(w, r, fmt.Errorf("panic: %v", recovered), stack)
+			}
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ServerError logs err and responds with a 500 error page. In debug mode the
+// page shows err, the stack trace and the request instead.
+//
+// Example:
+//
+//	if err != nil {
+//	    d.ServerError(w, r, err)
+//	    return
+//	}
+func (d *Devify) ServerError(w http.ResponseWriter, r *http.Request, err error) {
+	if d.ErrorLog != nil {
+		d.ErrorLog.Println(err)
+	}
+	d.serverError(w, r, err, debug.Stack())
+}
+
+func (d *Devify) serverError(w http.ResponseWriter, r *http.Request, err error, stack []byte) {
+	if d.Debug && r != nil && !wantsJSON(r) {
+		d.debugErrorPage(w, r, err, stack)
+		return
+	}
+	d.ErrorStatus(w, r, http.StatusInternalServerError)
+}
+
+// ErrorStatus responds with an error page for status:
+//
+//   - API requests (preferring JSON) get an application/problem+json body;
+//   - otherwise views/errors/<status> is rendered when it exists, with
+//     .Data["status"] and .Data["title"] set;
+//   - otherwise the status text is sent as plain text.
+//
+// The title is translated through the errors.<status> catalog key. r may be
+// nil, in which case the template is rendered without request data.
+func (d *Devify) ErrorStatus(w http.ResponseWriter, r *http.Request, status int) {
+	title := http.StatusText(status)
+	if r != nil {
+		title = d.statusTitle(r, status)
+	}
+
+	if r != nil && wantsJSON(r) {
+		d.writeProblem(w, r, Problem{Type: "about:blank", Title: title, Status: status})
+		return
+	}
+
+	view := "errors/" + strconv.Itoa(status)
+	if d.Render != nil && d.Render.Exists(view) {
+		data := &render.TemplateData{Data: map[string]interface{}{"status": status, "title": title}}
+
+		var buf bytes.Buffer
+		err := d.Render.Page(&bufferedResponse{ResponseWriter: w, buf: &buf}, r, view, data, nil)
+		if err == nil {
+			_ = writeHTML(w, status, &buf)
+			return
+		}
+		if d.ErrorLog != nil {
+			d.ErrorLog.Printf("rendering error page %s: %v", view, err)
+		}
+	}
+
+	http.Error(w, title, status)
+}
+
+// statusTitle returns the translated status text of status.
+func (d *Devify) statusTitle(r *http.Request, status int) string {
+	if d.I18n != nil {
+		if title, ok := d.I18n.Lookup(d.Locale(r), "errors."+strconv.Itoa(status)); ok {
+			return title
+		}
+	}
+	return http.StatusText(status)
+}
+
+// debugHeaders are not shown on the debug error page.
+var debugHeaders = map[string]bool{"Authorization": true, "Cookie": true, "X-Csrf-Token": true}
+
+var debugErrorTemplate = template.Must(template.New("debug").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>500 {{.Error}}</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2rem; color: #222; }
+h1 { color: #b00020; font-size: 1.4rem; }
+pre { background: #f5f5f5; padding: 1rem; overflow-x: auto; font-size: .85rem; }
+th { text-align: left; padding-right: 1rem; vertical-align: top; }
+</style>
+</head>
+<body>
+<h1>{{.Error}}</h1>
+<p>This page is shown because DEBUG is true.</p>
+<h2>Request</h2>
+<table>
+<tr><th>Method</th><td>{{.Method}}</td></tr>
+<tr><th>URL</th><td>{{.URL}}</td></tr>
+<tr><th>Remote address</th><td>{{.RemoteAddr}}</td></tr>
+{{range .Headers}}<tr><th>{{index . 0}}</th><td>{{index . 1}}</td></tr>
+{{end}}</table>
+<h2>Stack trace</h2>
+<pre>{{.Stack}}</pre>
+</body>
+</html>
+`))
+
+// debugErrorPage shows err, the stack and the request. It is only used in debug mode.
+func (d *Devify) debugErrorPage(w http.ResponseWriter, r *http.Request, err error, stack []byte) {
+	var headers [][2]string
+	for name, values := range r.Header {
+		value := strings.Join(values, ", ")
+		if debugHeaders[name] {
+			value = "[hidden]"
+		}
+		headers = append(headers, [2]string{name, value})
+	}
+	sort.Slice(headers, func(i, j int) bool { return headers[i][0] < headers[j][0] })
+
+	var buf bytes.Buffer
+	err = debugErrorTemplate.Execute(&buf, map[string]interface{}{
+		"Error":      err.Error(),
+		"Method":     r.Method,
+		"URL":        r.URL.String(),
+		"RemoteAddr": r.RemoteAddr,
+		"Headers":    headers,
+		"Stack":      string(stack),
+	})
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	_ = writeHTML(w, http.StatusInternalServerError, &buf)
+}
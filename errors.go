@@ -0,0 +1,238 @@
+package devify
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// Error is an application error carrying what a client needs to know about a
+// failure. Message, Code and Fields are sent to the client; Err is only logged.
+//
+// Example:
+//
+//	user, err := models.Users.Get(id)
+//	if err != nil {
+//	    return devify.NewError(http.StatusNotFound, "user_not_found", "No user with that ID.").Wrap(err)
+//	}
+type Error struct {
+	Status  int               // HTTP status; 500 when zero
+	Code    string            // machine-readable code, e.g. "user_not_found"
+	Message string            // human-readable detail, safe to show to clients
+	Fields  map[string]string // errors per field, as in Validation.Errors
+	Err     error             // underlying cause, never sent to clients
+}
+
+// NewError returns an *Error with the given status, code and message.
+func NewError(status int, code, message string) *Error {
+	return &Error{Status: status, Code: code, Message: message}
+}
+
+// Wrap sets the underlying cause of e and returns e.
+func (e *Error) Wrap(err error) *Error {
+	e.Err = err
+	return e
+}
+
+func (e *Error) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.status())
+	}
+	if e.Code != "" {
+		msg = e.Code + ": " + msg
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func (e *Error) status() int {
+	if e.Status == 0 {
+		return http.StatusInternalServerError
+	}
+	return e.Status
+}
+
+// Problem is an RFC 7807 problem details body.
+type Problem struct {
+	Type     string            `json:"type"`
+	Title    string            `json:"title"`
+	Status   int               `json:"status"`
+	Detail   string            `json:"detail,omitempty"`
+	Instance string            `json:"instance,omitempty"`
+	Code     string            `json:"code,omitempty"`
+	Errors   map[string]string `json:"errors,omitempty"`
+}
+
+// problemFields and problemField are the XML form of Problem.Errors.
+type problemFields struct {
+	Errors []problemField `xml:"error"`
+}
+
+type problemField struct {
+	Field   string `xml:"field,attr"`
+	Message string `xml:",chardata"`
+}
+
+// MarshalXML encodes p in the urn:ietf:rfc:7807 namespace, with field errors
+// as <errors><error field="name">message</error></errors>.
+func (p Problem) MarshalXML(e *xml.Encoder, _ xml.StartElement) error {
+	var fields *problemFields
+	if len(p.Errors) > 0 {
+		fields = &problemFields{}
+		for field, message := range p.Errors {
+			fields.Errors = append(fields.Errors, problemField{Field: field, Message: message})
+		}
+		sort.Slice(fields.Errors, func(i, j int) bool { return fields.Errors[i].Field < fields.Errors[j].Field })
+	}
+
+	return e.Encode(struct {
+		XMLName  xml.Name       `xml:"urn:ietf:rfc:7807 problem"`
+		Type     string         `xml:"type"`
+		Title    string         `xml:"title"`
+		Status   int            `xml:"status"`
+		Detail   string         `xml:"detail,omitempty"`
+		Instance string         `xml:"instance,omitempty"`
+		Code     string         `xml:"code,omitempty"`
+		Errors   *problemFields `xml:"errors,omitempty"`
+	}{
+		Type:     p.Type,
+		Title:    p.Title,
+		Status:   p.Status,
+		Detail:   p.Detail,
+		Instance: p.Instance,
+		Code:     p.Code,
+		Errors:   fields,
+	})
+}
+
+// WriteError writes err as an application/problem+json response, or
+// application/problem+xml when the client prefers XML:
+//
+//   - an *Error (or an error wrapping one) uses its status, code, message and fields;
+//   - a failed *Validation becomes 422 Unprocessable Entity with its errors,
+//     unless a rule couldn't be checked (see Validation.Err);
+//   - any other error is a 500 whose message is hidden unless DEBUG is true.
+//
+// Server errors (5xx) are logged with their cause.
+//
+// Example:
+//
+//	v := d.Validator(r)
+//	v.RequiredFields([]string{"email"})
+//	if !v.Valid() {
+//	    d.WriteError(w, r, v)
+//	    return
+//	}
+func (d *Devify) WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	problem := d.problemFor(r, err)
+	if problem.Status >= http.StatusInternalServerError && d.ErrorLog != nil {
+		d.ErrorLog.Printf("%s %s: %v", r.Method, r.URL.Path, err)
+	}
+	d.writeProblem(w, r, problem)
+}
+
+// problemFor converts err into a Problem for r.
+func (d *Devify) problemFor(r *http.Request, err error) Problem {
+	problem := Problem{Type: "about:blank", Instance: r.URL.Path}
+
+	var appErr *Error
+	var validation *Validation
+	switch {
+	case errors.As(err, &validation) && validation.Err() == nil:
+		problem.Status = http.StatusUnprocessableEntity
+		problem.Detail = validation.message("failed", "The given data was invalid.")
+		problem.Code = "validation_failed"
+		problem.Errors = validation.Errors
+	case errors.As(err, &appErr):
+		problem.Status = appErr.status()
+		problem.Detail = appErr.Message
+		problem.Code = appErr.Code
+		problem.Errors = appErr.Fields
+	default:
+		problem.Status = http.StatusInternalServerError
+		if d.Debug && err != nil {
+			problem.Detail = err.Error()
+		}
+	}
+
+	problem.Title = d.statusTitle(r, problem.Status)
+	return problem
+}
+
+// writeProblem writes problem as JSON or, when the client prefers it, XML.
+func (d *Devify) writeProblem(w http.ResponseWriter, r *http.Request, problem Problem) {
+	var body []byte
+	var err error
+	if negotiate(r.Header.Get("Accept"), []string{mediaJSON, mediaXML}) == mediaXML {
+		w.Header().Set("Content-Type", "application/problem+xml")
+		body, err = xml.Marshal(problem)
+	} else {
+		w.Header().Set("Content-Type", "application/problem+json")
+		body, err = json.Marshal(problem)
+	}
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(problem.Status)
+	_, _ = w.Write(body)
+}
+
+// HandlerFunc is an HTTP handler that returns an error instead of writing it.
+type HandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// Handle adapts fn to an http.HandlerFunc that responds to the error fn returns:
+// API clients get WriteError; browsers are redirected back with the flashed
+// input for a failed *Validation, get the error page for the status of an
+// *Error, or ServerError for anything else. An *Error with a 5xx status is
+// logged like a server error but keeps its status, e.g. 503, unless Debug is
+// set, which shows the debug page instead.
+//
+// Example:
+//
+//	app.Routes.Post("/users", app.Handle(func(w http.ResponseWriter, r *http.Request) error {
+//	    v := app.Validator(r)
+//	    v.RequiredFields([]string{"email"}).IsEmail("email")
+//	    if !v.Valid() {
+//	        return v
+//	    }
+//	    return app.Respond(w, r, http.StatusCreated, "users/show", data)
+//	}))
+func (d *Devify) Handle(fn HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := fn(w, r)
+		if err == nil {
+			return
+		}
+
+		if negotiate(r.Header.Get("Accept"), respondOffers) != mediaHTML || wantsJSON(r) {
+			d.WriteError(w, r, err)
+			return
+		}
+
+		var appErr *Error
+		var validation *Validation
+		switch {
+		case errors.As(err, &validation) && validation.Err() == nil:
+			d.RedirectBack(w, r, validation)
+		case errors.As(err, &appErr) && (appErr.status() < http.StatusInternalServerError || !d.Debug):
+			if appErr.status() >= http.StatusInternalServerError && d.ErrorLog != nil {
+				d.ErrorLog.Printf("%s %s: %v", r.Method, r.URL.Path, err)
+			}
+			d.ErrorStatus(w, r, appErr.status())
+		default:
+			d.ServerError(w, r, fmt.Errorf("%s %s: %w", r.Method, r.URL.Path, err))
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package devify
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWriteError(t *testing.T) {
+	notFound := NewError(http.StatusNotFound, "user_not_found", "No user with that ID.")
+
+	tests := []struct {
+		name        string
+		err         error
+		debug       bool
+		accept      string
+		status      int
+		contentType string
+		body        []string
+		hidden      string // must not appear in the body
+	}{
+		{"app error", notFound, false, "", http.StatusNotFound, "application/problem+json",
+			[]string{`"code":"user_not_found"`, `"detail":"No user with that ID."`, `"title":"Not Found"`, `"instance":"/users/1"`}, ""},
+		{"wrapped app error", fmt.Errorf("loading: %w", notFound), false, "", http.StatusNotFound, "application/problem+json",
+			[]string{`"code":"user_not_found"`}, "loading"},
+		{"cause is not sent", NewError(http.StatusConflict, "taken", "Taken.").Wrap(errors.New("pq: duplicate key")), false, "",
+			http.StatusConflict, "application/problem+json", []string{`"status":409`}, "duplicate key"},
+		{"zero status", &Error{Code: "oops"}, false, "", http.StatusInternalServerError, "application/problem+json",
+			[]string{`"code":"oops"`}, ""},
+		{"validation", &Validation{Errors: map[string]string{"email": "Email is required."}}, false, "",
+			http.StatusUnprocessableEntity, "application/problem+json",
+			[]string{`"code":"validation_failed"`, `"errors":{"email":"Email is required."}`}, ""},
+		{"plain error", errors.New("secret dsn"), false, "", http.StatusInternalServerError, "application/problem+json",
+			[]string{`"title":"Internal Server Error"`}, "secret dsn"},
+		{"plain error in debug", errors.New("secret dsn"), true, "", http.StatusInternalServerError, "application/problem+json",
+			[]string{`"detail":"secret dsn"`}, ""},
+		{"XML", &Validation{Errors: map[string]string{"email": "Email is required."}}, false, "application/xml",
+			http.StatusUnprocessableEntity, "application/problem+xml",
+			[]string{`<problem xmlns="urn:ietf:rfc:7807">`, `<error field="email">Email is required.</error>`}, ""},
+		{"XML without fields", notFound, false, "application/xml", http.StatusNotFound, "application/problem+xml",
+			[]string{"<code>user_not_found</code>"}, "<errors>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newTestApp(t)
+			d.Debug = tt.debug
+			r := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			d.WriteError(w, r, tt.err)
+
+			if w.Code != tt.status {
+				t.Errorf("status = %d; want %d", w.Code, tt.status)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != tt.contentType {
+				t.Errorf("Content-Type = %q; want %q", ct, tt.contentType)
+			}
+			for _, want := range tt.body {
+				if !strings.Contains(w.Body.String(), want) {
+					t.Errorf("body %s does not contain %s", w.Body.String(), want)
+				}
+			}
+			if tt.hidden != "" && strings.Contains(w.Body.String(), tt.hidden) {
+				t.Errorf("body %s contains %s", w.Body.String(), tt.hidden)
+			}
+		})
+	}
+}
+
+func TestHandle(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		accept   string
+		debug    bool
+		status   int
+		location string
+		logged   bool
+	}{
+		{"no error", nil, "", false, http.StatusOK, "", false},
+		{"API validation", &Validation{Errors: map[string]string{"email": "required"}}, "application/json", false, http.StatusUnprocessableEntity, "", false},
+		{"API app error", NewError(http.StatusNotFound, "", ""), "application/json", false, http.StatusNotFound, "", false},
+		{"browser validation", &Validation{Errors: map[string]string{"email": "required"}}, "text/html", false, http.StatusSeeOther, "/form", false},
+		{"browser app error", NewError(http.StatusNotFound, "", ""), "text/html", false, http.StatusNotFound, "", false},
+		{"browser server app error", NewError(http.StatusServiceUnavailable, "", ""), "text/html", false, http.StatusServiceUnavailable, "", true},
+		{"browser server app error in debug", NewError(http.StatusServiceUnavailable, "", ""), "text/html", true, http.StatusInternalServerError, "", true},
+		{"browser plain error", errors.New("boom"), "text/html", false, http.StatusInternalServerError, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newTestApp(t)
+			d.Debug = tt.debug
+			var logs bytes.Buffer
+			d.ErrorLog = log.New(&logs, "", 0)
+			useViews(d, nil)
+			h := d.Handle(func(w http.ResponseWriter, r *http.Request) error {
+				if tt.err == nil {
+					w.WriteHeader(http.StatusOK)
+				}
+				return tt.err
+			})
+
+			r := withSession(t, d, httptest.NewRequest(http.MethodPost, "/users", nil))
+			r.Header.Set("Accept", tt.accept)
+			r.Header.Set("Referer", "/form")
+			w := httptest.NewRecorder()
+			h(w, r)
+
+			if w.Code != tt.status {
+				t.Errorf("status = %d; want %d", w.Code, tt.status)
+			}
+			if loc := w.Header().Get("Location"); loc != tt.location {
+				t.Errorf("Location = %q; want %q", loc, tt.location)
+			}
+			if logged := logs.Len() > 0; logged != tt.logged {
+				t.Errorf("logged %q; want logged %v", logs.String(), tt.logged)
+			}
+			if tt.accept == "application/json" {
+				var problem Problem
+				if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil || problem.Status != tt.status {
+					t.Errorf("problem = %+v, %v", problem, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+// Package filesystem stores files on local disk, S3-compatible object storage
+// or an SFTP server behind a single Storage interface.
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"time"
+)
+
+// ErrNotExist is returned (possibly wrapped) for files that don't exist. It is
+// fs.ErrNotExist, so errors.Is(err, fs.ErrNotExist) works too.
+var ErrNotExist = fs.ErrNotExist
+
+// ErrNotSupported is returned by drivers that can't perform an operation, such
+// as TemporaryURL on SFTP.
+var ErrNotSupported = errors.New("filesystem: operation not supported by this driver")
+
+// Storage defines the operations every driver provides. Paths are
+// slash-separated and relative to the root of the storage; leading slashes
+// and ".." elements can't escape it.
+type Storage interface {
+	// Put stores the contents of r at path, replacing any existing file.
+	Put(ctx context.Context, path string, r io.Reader) error
+	// Get opens the file at path. The caller must close it.
+	Get(ctx context.Context, path string) (io.ReadCloser, error)
+	// Delete removes the file at path. Deleting a missing file is not an error.
+	Delete(ctx context.Context, path string) error
+	// List returns the files below prefix, recursively, sorted by path.
+	List(ctx context.Context, prefix string) ([]FileInfo, error)
+	// Exists reports whether a file exists at path.
+	Exists(ctx context.Context, path string) (bool, error)
+	// Stat describes the file at path.
+	Stat(ctx context.Context, path string) (FileInfo, error)
+	// TemporaryURL returns a URL that gives access to the file at path until
+	// expires has passed.
+	TemporaryURL(ctx context.Context, path string, expires time.Duration) (string, error)
+}
+
+// FileInfo describes a stored file.
+type FileInfo struct {
+	Path    string // relative to the root of the storage
+	Size    int64
+	ModTime time.Time
+}
+
+// clean returns name as a relative slash-separated path that stays inside the
+// root of the storage.
+func clean(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+strings.ReplaceAll(name, `\`, "/")), "/")
+}
@@ -0,0 +1,258 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/sftp"
+)
+
+// testStorage runs the behavior every driver must share against s.
+func testStorage(t *testing.T, s Storage) {
+	t.Helper()
+	ctx := context.Background()
+
+	files := map[string]string{
+		"a.txt":         "alpha",
+		"docs/b.txt":    "bravo",
+		"docs/sub/c.md": "charlie",
+		"/../escape.md": "delta", // stays inside the storage as escape.md
+	}
+	for name, content := range files {
+		if err := s.Put(ctx, name, strings.NewReader(content)); err != nil {
+			t.Fatalf("Put %s: %v", name, err)
+		}
+	}
+	if err := s.Put(ctx, "a.txt", strings.NewReader("alpha2")); err != nil {
+		t.Fatalf("Put over an existing file: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		exists  bool
+	}{
+		{"replaced file", "a.txt", "alpha2", true},
+		{"nested file", "docs/sub/c.md", "charlie", true},
+		{"escaping path", "escape.md", "delta", true},
+		{"missing file", "missing.txt", "", false},
+		{"directory", "docs", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exists, err := s.Exists(ctx, tt.path)
+			if err != nil || exists != tt.exists {
+				t.Fatalf("Exists = %v, %v; want %v", exists, err, tt.exists)
+			}
+
+			info, err := s.Stat(ctx, tt.path)
+			rc, getErr := s.Get(ctx, tt.path)
+			if !tt.exists {
+				if !errors.Is(err, fs.ErrNotExist) {
+					t.Errorf("Stat error = %v; want ErrNotExist", err)
+				}
+				if !errors.Is(getErr, fs.ErrNotExist) {
+					t.Errorf("Get error = %v; want ErrNotExist", getErr)
+				}
+				if rc != nil {
+					rc.Close()
+				}
+				return
+			}
+
+			if err != nil || info.Size != int64(len(tt.content)) || info.Path != tt.path {
+				t.Errorf("Stat = %+v, %v", info, err)
+			}
+			if getErr != nil {
+				t.Fatal(getErr)
+			}
+			defer rc.Close()
+			got, _ := io.ReadAll(rc)
+			if string(got) != tt.content {
+				t.Errorf("Get = %q; want %q", got, tt.content)
+			}
+		})
+	}
+
+	list, err := s.List(ctx, "docs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, f := range list {
+		paths = append(paths, f.Path)
+	}
+	if want := []string{"docs/b.txt", "docs/sub/c.md"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("List(docs) = %v; want %v", paths, want)
+	}
+	if list, err := s.List(ctx, "nothing"); err != nil || len(list) != 0 {
+		t.Errorf("List of a missing prefix = %v, %v", list, err)
+	}
+
+	if err := s.Delete(ctx, "docs/b.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if exists, _ := s.Exists(ctx, "docs/b.txt"); exists {
+		t.Error("file still exists after Delete")
+	}
+	if err := s.Delete(ctx, "docs/b.txt"); err != nil {
+		t.Errorf("deleting a missing file: %v", err)
+	}
+}
+
+func TestLocal(t *testing.T) {
+	local, err := NewLocal(t.TempDir(), "/storage", []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStorage(t, local)
+}
+
+func TestLocal_TemporaryURL(t *testing.T) {
+	ctx := context.Background()
+	local, err := NewLocal(t.TempDir(), "/storage", []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := local.Put(ctx, "reports/q 1.txt", strings.NewReader("numbers")); err != nil {
+		t.Fatal(err)
+	}
+
+	valid, err := local.TemporaryURL(ctx, "reports/q 1.txt", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(valid, "/storage/reports/q%201.txt?") {
+		t.Fatalf("unexpected URL %s", valid)
+	}
+	expired, _ := local.TemporaryURL(ctx, "reports/q 1.txt", -time.Minute)
+
+	u, _ := url.Parse(valid)
+	q := u.Query()
+	q.Set("signature", "forged")
+	u.RawQuery = q.Encode()
+	forged := u.String()
+
+	missing, _ := local.TemporaryURL(ctx, "reports/none.txt", time.Minute)
+
+	handler := http.StripPrefix("/storage", local.Handler())
+	tests := []struct {
+		name   string
+		url    string
+		status int
+		body   string
+	}{
+		{"valid", valid, http.StatusOK, "numbers"},
+		{"expired", expired, http.StatusForbidden, ""},
+		{"forged", forged, http.StatusForbidden, ""},
+		{"unsigned", "/storage/reports/q%201.txt", http.StatusForbidden, ""},
+		{"missing", missing, http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tt.url, nil))
+			if rr.Code != tt.status {
+				t.Fatalf("status = %d; want %d", rr.Code, tt.status)
+			}
+			if tt.body != "" && rr.Body.String() != tt.body {
+				t.Errorf("body = %q; want %q", rr.Body.String(), tt.body)
+			}
+		})
+	}
+
+	if _, err := (&Local{Root: t.TempDir()}).TemporaryURL(ctx, "x", time.Minute); err == nil {
+		t.Error("TemporaryURL without a signing key succeeded")
+	}
+}
+
+func TestSFTP(t *testing.T) {
+	for _, root := range []string{"/data", "/", "data/", ""} {
+		t.Run("root "+root, func(t *testing.T) {
+			// Connect a client to an in-memory SFTP server through two pipes.
+			serverRead, clientWrite := io.Pipe()
+			clientRead, serverWrite := io.Pipe()
+			server := sftp.NewRequestServer(struct {
+				io.Reader
+				io.WriteCloser
+			}{serverRead, serverWrite}, sftp.InMemHandler())
+			go server.Serve()
+
+			client, err := sftp.NewClientPipe(clientRead, clientWrite)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := NewSFTPFromClient(client, root)
+			defer func() {
+				// Stop the server first; the client waits for its end of the pipe to close.
+				server.Close()
+				s.Close()
+			}()
+
+			testStorage(t, s)
+
+			if _, err := s.TemporaryURL(context.Background(), "a.txt", time.Minute); !errors.Is(err, ErrNotSupported) {
+				t.Errorf("TemporaryURL error = %v; want ErrNotSupported", err)
+			}
+		})
+	}
+}
+
+// TestS3 runs against an S3-compatible server such as a local MinIO, e.g.:
+//
+//	docker run -p 9000:9000 minio/minio server /data
+//	S3_TEST_ENDPOINT=localhost:9000 S3_TEST_BUCKET=test go test ./filesystem
+//
+// The bucket must exist. Credentials default to MinIO's minioadmin.
+func TestS3(t *testing.T) {
+	endpoint := os.Getenv("S3_TEST_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("S3_TEST_ENDPOINT not set")
+	}
+
+	key, secret := os.Getenv("S3_TEST_KEY"), os.Getenv("S3_TEST_SECRET")
+	if key == "" {
+		key, secret = "minioadmin", "minioadmin"
+	}
+	s, err := NewS3(S3Config{
+		Endpoint:  endpoint,
+		Bucket:    os.Getenv("S3_TEST_BUCKET"),
+		AccessKey: key,
+		SecretKey: secret,
+		PathStyle: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testStorage(t, s)
+
+	ctx := context.Background()
+	link, err := s.TemporaryURL(ctx, "a.txt", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.Get(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if body, _ := io.ReadAll(resp.Body); resp.StatusCode != http.StatusOK || string(body) != "alpha2" {
+		t.Errorf("GET temporary URL = %d %q", resp.StatusCode, body)
+	}
+
+	for _, name := range []string{"a.txt", "docs/sub/c.md", "escape.md"} {
+		_ = s.Delete(ctx, name)
+	}
+}
@@ -0,0 +1,201 @@
+package filesystem
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Local stores files in a directory on disk. Temporary URLs point at
+// BaseURL, where Handler must be mounted to serve them.
+type Local struct {
+	Root       string // directory the files are stored in
+	BaseURL    string // URL Handler is mounted at, e.g. "/storage" or "https://example.com/storage"
+	SigningKey []byte // key signing temporary URLs; TemporaryURL fails without it
+}
+
+// NewLocal returns a Local storage in root, creating the directory if needed.
+func NewLocal(root, baseURL string, signingKey []byte) (*Local, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+	return &Local{Root: root, BaseURL: strings.TrimSuffix(baseURL, "/"), SigningKey: signingKey}, nil
+}
+
+// file returns the OS path of name.
+func (l *Local) file(name string) string {
+	return filepath.Join(l.Root, filepath.FromSlash(clean(name)))
+}
+
+// Put writes r to a temporary file next to path and renames it into place,
+// so readers never see a partial file.
+func (l *Local) Put(_ context.Context, path string, r io.Reader) error {
+	target := l.file(path)
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(tmp, r); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), target)
+}
+
+func (l *Local) Get(_ context.Context, path string) (io.ReadCloser, error) {
+	f, err := os.Open(l.file(path))
+	if err != nil {
+		return nil, err
+	}
+	if info, err := f.Stat(); err != nil || info.IsDir() {
+		_ = f.Close()
+		return nil, &fs.PathError{Op: "open", Path: path, Err: ErrNotExist}
+	}
+	return f, nil
+}
+
+func (l *Local) Delete(_ context.Context, path string) error {
+	err := os.Remove(l.file(path))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (l *Local) List(_ context.Context, prefix string) ([]FileInfo, error) {
+	var files []FileInfo
+	err := filepath.WalkDir(l.file(prefix), func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(l.Root, name)
+		if err != nil {
+			return err
+		}
+		files = append(files, FileInfo{Path: filepath.ToSlash(rel), Size: info.Size(), ModTime: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+func (l *Local) Exists(ctx context.Context, path string) (bool, error) {
+	_, err := l.Stat(ctx, path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (l *Local) Stat(_ context.Context, path string) (FileInfo, error) {
+	info, err := os.Stat(l.file(path))
+	if err != nil {
+		return FileInfo{}, err
+	}
+	if info.IsDir() {
+		return FileInfo{}, &fs.PathError{Op: "stat", Path: path, Err: ErrNotExist}
+	}
+	return FileInfo{Path: clean(path), Size: info.Size(), ModTime: info.ModTime()}, nil
+}
+
+// TemporaryURL returns BaseURL/path with an expiry time and an HMAC signature
+// that Handler checks.
+func (l *Local) TemporaryURL(_ context.Context, path string, expires time.Duration) (string, error) {
+	if len(l.SigningKey) == 0 {
+		return "", errors.New("filesystem: local temporary URLs need a signing key")
+	}
+
+	name := clean(path)
+	expiresAt := strconv.FormatInt(time.Now().Add(expires).Unix(), 10)
+
+	segments := strings.Split(name, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	query := url.Values{"expires": {expiresAt}, "signature": {l.sign(name, expiresAt)}}
+	return fmt.Sprintf("%s/%s?%s", l.BaseURL, strings.Join(segments, "/"), query.Encode()), nil
+}
+
+// sign returns the signature of a temporary URL for name.
+func (l *Local) sign(name, expiresAt string) string {
+	mac := hmac.New(sha256.New, l.SigningKey)
+	mac.Write([]byte(name + "\n" + expiresAt))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Handler serves the files of temporary URLs, answering 403 Forbidden for
+// missing, invalid or expired signatures. Mount it at BaseURL without the
+// prefix, e.g.:
+//
+//	app.Routes.Handle("/storage/*", http.StripPrefix("/storage", local.Handler()))
+func (l *Local) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := clean(r.URL.Path)
+		expiresAt := r.URL.Query().Get("expires")
+
+		unix, err := strconv.ParseInt(expiresAt, 10, 64)
+		valid := err == nil && len(l.SigningKey) > 0 && time.Now().Unix() <= unix &&
+			hmac.Equal([]byte(r.URL.Query().Get("signature")), []byte(l.sign(name, expiresAt)))
+		if !valid {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+
+		f, err := os.Open(l.file(name))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		defer f.Close()
+
+		info, err := f.Stat()
+		if err != nil || info.IsDir() {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Cache-Control", "private, no-store")
+		http.ServeContent(w, r, info.Name(), info.ModTime(), f)
+	})
+}
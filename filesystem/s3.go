@@ -0,0 +1,146 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// s3PartSize is the part size of multipart uploads of streams of unknown size,
+// which bounds the memory Put buffers per upload.
+const s3PartSize = 16 << 20 // 16MB
+
+// S3Config holds the settings of an S3-compatible object storage, such as AWS
+// S3, MinIO, Cloudflare R2 or DigitalOcean Spaces.
+type S3Config struct {
+	Endpoint  string // host[:port] without scheme, e.g. "s3.amazonaws.com" or "localhost:9000"
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	UseSSL    bool
+	PathStyle bool // address buckets as endpoint/bucket instead of bucket.endpoint, as MinIO expects
+}
+
+// S3 stores files as objects of a bucket in S3-compatible object storage.
+type S3 struct {
+	Client *minio.Client
+	Bucket string
+}
+
+// NewS3 returns an S3 storage for cfg. It doesn't contact the server.
+func NewS3(cfg S3Config) (*S3, error) {
+	lookup := minio.BucketLookupAuto
+	if cfg.PathStyle {
+		lookup = minio.BucketLookupPath
+	}
+
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:        credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure:       cfg.UseSSL,
+		Region:       cfg.Region,
+		BucketLookup: lookup,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &S3{Client: client, Bucket: cfg.Bucket}, nil
+}
+
+// Put uploads r with the content type of the extension of path.
+func (s *S3) Put(ctx context.Context, path string, r io.Reader) error {
+	key := clean(path)
+	_, err := s.Client.PutObject(ctx, s.Bucket, key, r, -1, minio.PutObjectOptions{
+		ContentType: contentTypeOf(key),
+		PartSize:    s3PartSize,
+	})
+	return err
+}
+
+func (s *S3) Get(ctx context.Context, path string) (io.ReadCloser, error) {
+	key := clean(path)
+	obj, err := s.Client.GetObject(ctx, s.Bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, s.pathError("open", key, err)
+	}
+	// GetObject is lazy; Stat surfaces a missing object now instead of on Read.
+	if _, err := obj.Stat(); err != nil {
+		_ = obj.Close()
+		return nil, s.pathError("open", key, err)
+	}
+	return obj, nil
+}
+
+func (s *S3) Delete(ctx context.Context, path string) error {
+	return s.Client.RemoveObject(ctx, s.Bucket, clean(path), minio.RemoveObjectOptions{})
+}
+
+func (s *S3) List(ctx context.Context, prefix string) ([]FileInfo, error) {
+	dir := clean(prefix)
+	if dir != "" {
+		dir += "/"
+	}
+
+	var files []FileInfo
+	for obj := range s.Client.ListObjects(ctx, s.Bucket, minio.ListObjectsOptions{Prefix: dir, Recursive: true}) {
+		if obj.Err != nil {
+			return nil, obj.Err
+		}
+		files = append(files, FileInfo{Path: obj.Key, Size: obj.Size, ModTime: obj.LastModified})
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+func (s *S3) Exists(ctx context.Context, path string) (bool, error) {
+	_, err := s.Stat(ctx, path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *S3) Stat(ctx context.Context, path string) (FileInfo, error) {
+	key := clean(path)
+	info, err := s.Client.StatObject(ctx, s.Bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		return FileInfo{}, s.pathError("stat", key, err)
+	}
+	return FileInfo{Path: key, Size: info.Size, ModTime: info.LastModified}, nil
+}
+
+// TemporaryURL returns a presigned GET URL, valid for at most 7 days.
+func (s *S3) TemporaryURL(ctx context.Context, path string, expires time.Duration) (string, error) {
+	u, err := s.Client.PresignedGetObject(ctx, s.Bucket, clean(path), expires, nil)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+// pathError wraps err, turning missing objects into ErrNotExist.
+func (s *S3) pathError(op, key string, err error) error {
+	resp := minio.ToErrorResponse(err)
+	if resp.Code == "NoSuchKey" || resp.StatusCode == http.StatusNotFound {
+		err = ErrNotExist
+	}
+	return &fs.PathError{Op: op, Path: key, Err: err}
+}
+
+// contentTypeOf returns the MIME type of the extension of key.
+func contentTypeOf(key string) string {
+	if contentType := mime.TypeByExtension(path.Ext(key)); contentType != "" {
+		return contentType
+	}
+	return "application/octet-stream"
+}
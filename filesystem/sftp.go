@@ -0,0 +1,213 @@
+package filesystem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// SFTPConfig holds the settings of an SFTP server. Authentication uses the
+// password, the private key or both.
+type SFTPConfig struct {
+	Host           string
+	Port           int // 22 when zero
+	User           string
+	Password       string
+	PrivateKey     []byte // PEM-encoded, unencrypted
+	KnownHostsFile string // known_hosts file verifying the server's host key
+	// InsecureIgnoreHostKey accepts any host key when KnownHostsFile is
+	// empty. Only use it in development.
+	InsecureIgnoreHostKey bool
+	Root                  string        // directory files are stored in
+	Timeout               time.Duration // connection timeout; 10 seconds when zero
+}
+
+// SFTP stores files below a directory of an SFTP server. The context of its
+// methods is not used, since SFTP requests can't be canceled.
+type SFTP struct {
+	Client *sftp.Client
+	Root   string
+	conn   *ssh.Client
+}
+
+// NewSFTP connects to the server of cfg. Call Close when done.
+func NewSFTP(cfg SFTPConfig) (*SFTP, error) {
+	var auth []ssh.AuthMethod
+	if cfg.Password != "" {
+		auth = append(auth, ssh.Password(cfg.Password))
+	}
+	if len(cfg.PrivateKey) > 0 {
+		signer, err := ssh.ParsePrivateKey(cfg.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("filesystem: parsing SFTP private key: %w", err)
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+
+	var hostKey ssh.HostKeyCallback
+	switch {
+	case cfg.KnownHostsFile != "":
+		var err error
+		if hostKey, err = knownhosts.New(cfg.KnownHostsFile); err != nil {
+			return nil, fmt.Errorf("filesystem: reading SFTP known hosts: %w", err)
+		}
+	case cfg.InsecureIgnoreHostKey:
+		hostKey = ssh.InsecureIgnoreHostKey()
+	default:
+		return nil, errors.New("filesystem: SFTP needs a known hosts file to verify the server")
+	}
+
+	port, timeout := cfg.Port, cfg.Timeout
+	if port == 0 {
+		port = 22
+	}
+	if timeout == 0 {
+		timeout = 10 * time.Second
+	}
+
+	conn, err := ssh.Dial("tcp", net.JoinHostPort(cfg.Host, strconv.Itoa(port)), &ssh.ClientConfig{
+		User:            cfg.User,
+		Auth:            auth,
+		HostKeyCallback: hostKey,
+		Timeout:         timeout,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := sftp.NewClient(conn)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	s := NewSFTPFromClient(client, cfg.Root)
+	s.conn = conn
+	return s, nil
+}
+
+// NewSFTPFromClient returns an SFTP storage below root using an existing client.
+func NewSFTPFromClient(client *sftp.Client, root string) *SFTP {
+	if root == "" {
+		root = "."
+	}
+	return &SFTP{Client: client, Root: root}
+}
+
+// Close closes the SFTP session and, if NewSFTP opened it, the SSH connection.
+func (s *SFTP) Close() error {
+	err := s.Client.Close()
+	if s.conn != nil {
+		if cerr := s.conn.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// file returns the remote path of name.
+func (s *SFTP) file(name string) string {
+	return path.Join(s.Root, clean(name))
+}
+
+func (s *SFTP) Put(_ context.Context, name string, r io.Reader) error {
+	target := s.file(name)
+	if err := s.Client.MkdirAll(path.Dir(target)); err != nil {
+		return err
+	}
+
+	f, err := s.Client.Create(target)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (s *SFTP) Get(ctx context.Context, name string) (io.ReadCloser, error) {
+	// Servers disagree on opening directories; Stat reports them as missing.
+	if _, err := s.Stat(ctx, name); err != nil {
+		return nil, err
+	}
+	f, err := s.Client.Open(s.file(name))
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: clean(name), Err: err}
+	}
+	return f, nil
+}
+
+func (s *SFTP) Delete(_ context.Context, name string) error {
+	err := s.Client.Remove(s.file(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s *SFTP) List(_ context.Context, prefix string) ([]FileInfo, error) {
+	root := s.file(prefix)
+	base := path.Clean(s.Root)
+
+	var files []FileInfo
+	walker := s.Client.Walk(root)
+	for walker.Step() {
+		if err := walker.Err(); err != nil {
+			if walker.Path() == root && errors.Is(err, fs.ErrNotExist) {
+				return nil, nil
+			}
+			return nil, err
+		}
+
+		info := walker.Stat()
+		if info.IsDir() {
+			continue
+		}
+		rel := walker.Path()
+		if base != "." {
+			rel = strings.TrimPrefix(strings.TrimPrefix(rel, base), "/")
+		}
+		files = append(files, FileInfo{Path: rel, Size: info.Size(), ModTime: info.ModTime()})
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+func (s *SFTP) Exists(ctx context.Context, name string) (bool, error) {
+	_, err := s.Stat(ctx, name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *SFTP) Stat(_ context.Context, name string) (FileInfo, error) {
+	info, err := s.Client.Stat(s.file(name))
+	if err != nil {
+		return FileInfo{}, &fs.PathError{Op: "stat", Path: clean(name), Err: err}
+	}
+	if info.IsDir() {
+		return FileInfo{}, &fs.PathError{Op: "stat", Path: clean(name), Err: ErrNotExist}
+	}
+	return FileInfo{Path: clean(name), Size: info.Size(), ModTime: info.ModTime()}, nil
+}
+
+// TemporaryURL is not supported: SFTP servers have no HTTP URLs.
+func (s *SFTP) TemporaryURL(context.Context, string, time.Duration) (string, error) {
+	return "", ErrNotSupported
+}
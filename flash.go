@@ -0,0 +1,78 @@
+package devify
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/jorgeSader/devify/render"
+)
+
+// Flash message kinds. Each maps to the field of render.TemplateData with the same name.
+const (
+	FlashInfo    = render.FlashKey
+	FlashError   = render.ErrorKey
+	FlashWarning = render.WarningKey
+)
+
+// Flash stores a one-time message of the given kind (FlashInfo, FlashError or
+// FlashWarning) in the session. It is removed from the session and exposed as
+// .Flash, .Error or .Warning the next time a page is rendered. Unknown kinds are
+// stored as FlashInfo.
+//
+// Example:
+//
+//	d.Flash(r.Context(), devify.FlashInfo, "Profile updated.")
+//	http.Redirect(w, r, "/profile", http.StatusSeeOther)
+func (d *Devify) Flash(ctx context.Context, kind, msg string) {
+	switch kind {
+	case FlashError, FlashWarning:
+	default:
+		kind = FlashInfo
+	}
+	d.Session.Put(ctx, kind, msg)
+}
+
+// FlashValidation stores the submitted form data and the validation errors of v
+// in the session so they are available as .OldInput and .ValidationErrors (or
+// through the .Old and .FieldError helpers) on the next render.
+// Password fields and the CSRF token are never stored.
+func (d *Devify) FlashValidation(ctx context.Context, v *Validation) {
+	old := url.Values{}
+	for field, values := range v.Data {
+		lower := strings.ToLower(field)
+		if strings.Contains(lower, "password") || lower == "csrf_token" {
+			continue
+		}
+		old[field] = values
+	}
+
+	d.Session.Put(ctx, render.OldInputKey, old)
+	if len(v.Errors) > 0 {
+		d.Session.Put(ctx, render.ValidationErrorsKey, v.Errors)
+	}
+}
+
+// RedirectBack flashes the old input and errors of v and redirects to the page
+// that submitted the form (the Referer), falling back to the current path.
+//
+// Example:
+//
+//	v := d.Validator(r)
+//	v.RequiredFields([]string{"email"}).IsEmail("email")
+//	if !v.Valid() {
+//	    d.RedirectBack(w, r, v)
+//	    return
+//	}
+func (d *Devify) RedirectBack(w http.ResponseWriter, r *http.Request, v *Validation) {
+	if v != nil {
+		d.FlashValidation(r.Context(), v)
+	}
+
+	target := r.URL.Path
+	if ref, err := url.Parse(r.Referer()); err == nil && ref.Path != "" && (ref.Host == "" || ref.Host == r.Host) {
+		target = ref.RequestURI()
+	}
+	http.Redirect(w, r, target, http.StatusSeeOther)
+}
@@ -8,6 +8,8 @@ import (
 	"errors"
 	"io"
 	"os"
+	"strconv"
+	"time"
 )
 
 const (
@@ -118,3 +120,38 @@ func (e *Encryption) Decrypt(cryptoText string) (string, error) {
 
 	return string(cypherText), nil
 }
+
+// envInt reads an integer from the environment variable key, returning def if it is unset or invalid.
+func envInt(key string, def int) int {
+	i, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return i
+}
+
+// envBool reads a boolean from the environment variable key, returning def if it is unset or invalid.
+func envBool(key string, def bool) bool {
+	b, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return b
+}
+
+// envDuration reads a duration from the environment variable key. Values may be Go
+// durations ("5s", "2m") or a plain number of seconds. It returns def if the
+// variable is unset or invalid.
+func envDuration(key string, def time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+	if dur, err := time.ParseDuration(value); err == nil {
+		return dur
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	return def
+}
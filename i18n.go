@@ -0,0 +1,102 @@
+package devify
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/jorgeSader/devify/i18n"
+)
+
+// LocaleSessionKey is the session key holding the locale chosen with SetLocale.
+const LocaleSessionKey = "locale"
+
+// Localize detects the locale of each request and stores it in the request
+// context, where it is read by Locale, T, the template t helper and Validation.
+// The first match wins:
+//
+//  1. the first path segment, when LOCALE_URL_PREFIX is true (/pt-BR/about is
+//     routed as /about);
+//  2. the locale saved in the session by SetLocale;
+//  3. the Accept-Language header;
+//  4. DEFAULT_LOCALE (defaults to "en").
+//
+// Only locales with a catalog in lang/ are considered. It is in the default router.
+func (d *Devify) Localize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if d.I18n == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		locale := ""
+		if d.config.locale.urlPrefix {
+			locale, r = d.stripLocalePrefix(r)
+		}
+		if locale == "" && d.Session != nil {
+			if saved, ok := d.I18n.Supported(d.Session.GetString(r.Context(), LocaleSessionKey)); ok {
+				locale = saved
+			}
+		}
+		if locale == "" {
+			locale = d.I18n.Match(r.Header.Get("Accept-Language"))
+			w.Header().Add("Vary", "Accept-Language")
+		}
+
+		w.Header().Set("Content-Language", locale)
+		next.ServeHTTP(w, r.WithContext(i18n.WithLocale(r.Context(), locale)))
+	})
+}
+
+// stripLocalePrefix removes a supported locale from the start of the path and returns it.
+func (d *Devify) stripLocalePrefix(r *http.Request) (string, *http.Request) {
+	segment, rest, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if segment == "" {
+		return "", r
+	}
+	locale, ok := d.I18n.Supported(segment)
+	if !ok {
+		return "", r
+	}
+
+	r2 := r.Clone(r.Context())
+	r2.URL.Path = "/" + rest
+	r2.URL.RawPath = ""
+	return locale, r2
+}
+
+// SetLocale remembers locale in the session for the following requests, e.g.,
+// from a language switcher. It reports false if locale has no catalog.
+func (d *Devify) SetLocale(ctx context.Context, locale string) bool {
+	locale, ok := d.I18n.Supported(locale)
+	if !ok {
+		return false
+	}
+	d.Session.Put(ctx, LocaleSessionKey, locale)
+	return true
+}
+
+// Locale returns the locale detected by Localize for r, or the default locale.
+func (d *Devify) Locale(r *http.Request) string {
+	if locale := i18n.LocaleFrom(r.Context()); locale != "" {
+		return locale
+	}
+	if d.I18n != nil {
+		return d.I18n.DefaultLocale()
+	}
+	return ""
+}
+
+// T translates key into the locale of r. args are key/value pairs filling the
+// message placeholders, and "count" selects the plural form.
+//
+// Example:
+//
+//	d.Flash(r.Context(), devify.FlashInfo, d.T(r, "profile.saved"))
+//	msg := d.T(r, "cart.items", "count", len(items))
+func (d *Devify) T(r *http.Request, key string, args ...interface{}) string {
+	if d.I18n == nil {
+		return i18n.Format(key, args...)
+	}
+	return d.I18n.T(d.Locale(r), key, args...)
+}
@@ -0,0 +1,321 @@
+// Package i18n loads message catalogs and translates keys into the locale of a request.
+//
+// Catalogs are JSON or YAML files named after their locale (lang/en.json,
+// lang/pt-BR.yaml). Nested objects are flattened into dotted keys, and an object
+// whose keys are all CLDR plural categories (zero, one, two, few, many, other)
+// is a pluralized message:
+//
+//	{
+//	  "welcome": "Welcome, {name}!",
+//	  "cart": {
+//	    "items": {"zero": "Your cart is empty.", "one": "{count} item", "other": "{count} items"}
+//	  }
+//	}
+//
+// Placeholders such as {name} are filled from key/value argument pairs, and the
+// "count" argument selects the plural form:
+//
+//	tr.T("en", "cart.items", "count", 3) // "3 items"
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+)
+
+// CountArg is the argument that selects the plural form of a message.
+const CountArg = "count"
+
+// message is a catalog entry: either plain text or a set of plural forms.
+type message struct {
+	text   string
+	plural map[string]string
+}
+
+// pluralForms maps CLDR category names to the forms returned by x/text.
+var pluralForms = map[string]plural.Form{
+	"zero":  plural.Zero,
+	"one":   plural.One,
+	"two":   plural.Two,
+	"few":   plural.Few,
+	"many":  plural.Many,
+	"other": plural.Other,
+}
+
+// Translator holds the catalogs of every supported locale.
+type Translator struct {
+	defaultLocale string
+
+	mu       sync.RWMutex
+	messages map[string]map[string]message
+	matcher  language.Matcher
+	locales  []string
+}
+
+// New returns a Translator with no messages. defaultLocale is used when a
+// request's locale is unsupported and for keys missing from its catalog.
+func New(defaultLocale string) *Translator {
+	tr := &Translator{
+		defaultLocale: Canonical(defaultLocale),
+		messages:      make(map[string]map[string]message),
+	}
+	tr.messages[tr.defaultLocale] = make(map[string]message)
+	tr.index()
+	return tr
+}
+
+// Canonical returns the BCP 47 form of locale (e.g., "pt-br" becomes "pt-BR").
+// Invalid locales are returned unchanged.
+func Canonical(locale string) string {
+	tag, err := language.Parse(locale)
+	if err != nil {
+		return locale
+	}
+	return tag.String()
+}
+
+// DefaultLocale returns the fallback locale.
+func (tr *Translator) DefaultLocale() string {
+	return tr.defaultLocale
+}
+
+// AddMessages merges messages into the catalog of locale. Values are strings,
+// nested maps (flattened into dotted keys) or maps of plural forms.
+func (tr *Translator) AddMessages(locale string, messages map[string]interface{}) error {
+	flat := make(map[string]message)
+	if err := flatten("", messages, flat); err != nil {
+		return fmt.Errorf("i18n: %s: %w", locale, err)
+	}
+
+	locale = Canonical(locale)
+
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	catalog, ok := tr.messages[locale]
+	if !ok {
+		catalog = make(map[string]message)
+		tr.messages[locale] = catalog
+	}
+	for key, msg := range flat {
+		catalog[key] = msg
+	}
+	tr.index()
+	return nil
+}
+
+// index rebuilds the locale list and matcher, default locale first. Callers hold mu.
+func (tr *Translator) index() {
+	tr.locales = tr.locales[:0]
+	for locale := range tr.messages {
+		if locale != tr.defaultLocale {
+			tr.locales = append(tr.locales, locale)
+		}
+	}
+	sort.Strings(tr.locales)
+	tr.locales = append([]string{tr.defaultLocale}, tr.locales...)
+
+	tags := make([]language.Tag, 0, len(tr.locales))
+	for _, locale := range tr.locales {
+		tags = append(tags, language.Make(locale))
+	}
+	tr.matcher = language.NewMatcher(tags)
+}
+
+// flatten converts a decoded catalog into dotted keys.
+func flatten(prefix string, values map[string]interface{}, out map[string]message) error {
+	for key, value := range values {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		switch v := value.(type) {
+		case string:
+			out[key] = message{text: v}
+		case map[string]interface{}:
+			if forms, ok := pluralMessage(v); ok {
+				out[key] = message{plural: forms}
+				continue
+			}
+			if err := flatten(key, v, out); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("message %q must be a string or an object, got %T", key, value)
+		}
+	}
+	return nil
+}
+
+// pluralMessage reports whether every key of v is a plural category with a string value.
+func pluralMessage(v map[string]interface{}) (map[string]string, bool) {
+	if len(v) == 0 {
+		return nil, false
+	}
+	forms := make(map[string]string, len(v))
+	for category, value := range v {
+		text, ok := value.(string)
+		if _, known := pluralForms[category]; !known || !ok {
+			return nil, false
+		}
+		forms[category] = text
+	}
+	return forms, true
+}
+
+// Locales returns the supported locales, default locale first.
+func (tr *Translator) Locales() []string {
+	tr.mu.RLock()
+	defer tr.mu.RUnlock()
+	return append([]string(nil), tr.locales...)
+}
+
+// Supported returns the canonical form of locale if it has a catalog.
+func (tr *Translator) Supported(locale string) (string, bool) {
+	locale = Canonical(locale)
+	tr.mu.RLock()
+	defer tr.mu.RUnlock()
+	_, ok := tr.messages[locale]
+	return locale, ok
+}
+
+// Match returns the supported locale that best fits an Accept-Language header,
+// or the default locale.
+func (tr *Translator) Match(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return tr.defaultLocale
+	}
+
+	tr.mu.RLock()
+	defer tr.mu.RUnlock()
+	_, index, confidence := tr.matcher.Match(tags...)
+	if confidence == language.No {
+		return tr.defaultLocale
+	}
+	return tr.locales[index]
+}
+
+// T translates key into locale, falling back to the parent locales (pt-BR, pt)
+// and then the default locale. It returns the key itself when no catalog has it.
+// args are key/value pairs filling {placeholders}; "count" selects the plural form.
+func (tr *Translator) T(locale, key string, args ...interface{}) string {
+	if s, ok := tr.Lookup(locale, key, args...); ok {
+		return s
+	}
+	return key
+}
+
+// Lookup is like T but reports whether key was found instead of returning it.
+func (tr *Translator) Lookup(locale, key string, args ...interface{}) (string, bool) {
+	if tr == nil {
+		return "", false
+	}
+
+	tr.mu.RLock()
+	defer tr.mu.RUnlock()
+
+	for _, candidate := range tr.fallbacks(locale) {
+		msg, ok := tr.messages[candidate][key]
+		if !ok {
+			continue
+		}
+		text := msg.text
+		if msg.plural != nil {
+			text = selectPlural(candidate, msg.plural, args)
+		}
+		return Format(text, args...), true
+	}
+	return "", false
+}
+
+// fallbacks returns locale, its parents and the default locale.
+func (tr *Translator) fallbacks(locale string) []string {
+	var chain []string
+	if tag, err := language.Parse(locale); err == nil {
+		for ; tag != language.Und; tag = tag.Parent() {
+			chain = append(chain, tag.String())
+		}
+	}
+	return append(chain, tr.defaultLocale)
+}
+
+// selectPlural picks the plural form for the count argument, using "zero" for a
+// count of 0 when the message defines it and "other" when a form is missing.
+func selectPlural(locale string, forms map[string]string, args []interface{}) string {
+	count, ok := countArg(args)
+	if !ok {
+		return forms["other"]
+	}
+
+	if text, ok := forms["zero"]; ok && count == 0 {
+		return text
+	}
+
+	abs := count
+	if abs < 0 {
+		abs = -abs
+	}
+	form := plural.Cardinal.MatchPlural(language.Make(locale), abs, 0, 0, 0, 0)
+	for category, f := range pluralForms {
+		if f == form {
+			if text, ok := forms[category]; ok {
+				return text
+			}
+		}
+	}
+	return forms["other"]
+}
+
+func countArg(args []interface{}) (int, bool) {
+	for i := 0; i+1 < len(args); i += 2 {
+		if name, ok := args[i].(string); !ok || name != CountArg {
+			continue
+		}
+		switch n := args[i+1].(type) {
+		case int:
+			return n, true
+		case int64:
+			return int(n), true
+		case float64:
+			return int(n), true
+		default:
+			parsed, err := strconv.Atoi(fmt.Sprint(n))
+			return parsed, err == nil
+		}
+	}
+	return 0, false
+}
+
+// Format replaces {name} placeholders in text with the matching values of the
+// key/value pairs in args. Unknown placeholders are left as they are.
+func Format(text string, args ...interface{}) string {
+	if len(args) < 2 || !strings.Contains(text, "{") {
+		return text
+	}
+
+	pairs := make([]string, 0, len(args))
+	for i := 0; i+1 < len(args); i += 2 {
+		pairs = append(pairs, "{"+fmt.Sprint(args[i])+"}", fmt.Sprint(args[i+1]))
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
+
+type localeContextKey struct{}
+
+// WithLocale returns a copy of ctx carrying locale.
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeContextKey{}, locale)
+}
+
+// LocaleFrom returns the locale stored in ctx by WithLocale, or "".
+func LocaleFrom(ctx context.Context) string {
+	locale, _ := ctx.Value(localeContextKey{}).(string)
+	return locale
+}
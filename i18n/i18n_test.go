@@ -0,0 +1,139 @@
+package i18n
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+)
+
+var testCatalogs = fstest.MapFS{
+	"lang/en.json": {Data: []byte(`{
+		"welcome": "Welcome, {name}!",
+		"cart": {"items": {"zero": "Your cart is empty.", "one": "{count} item", "other": "{count} items"}}
+	}`)},
+	"lang/pt-BR.yaml": {Data: []byte(`
+welcome: "Bem-vindo, {name}!"
+cart:
+  items:
+    one: "{count} item"
+    other: "{count} itens"
+`)},
+	"lang/pl.yml": {Data: []byte(`
+files:
+  one: "{count} plik"
+  few: "{count} pliki"
+  many: "{count} plików"
+  other: "{count} pliku"
+`)},
+	"lang/README.md": {Data: []byte(`ignored`)},
+}
+
+func TestLoad(t *testing.T) {
+	tr, err := Load(testCatalogs, "lang", "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := tr.Locales()
+	want := []string{"en", "pl", "pt-BR"}
+	if len(got) != len(want) {
+		t.Fatalf("Locales() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Locales() = %v, want %v", got, want)
+		}
+	}
+
+	empty, err := Load(fstest.MapFS{}, "lang", "en")
+	if err != nil || len(empty.Locales()) != 1 {
+		t.Errorf("expected an empty translator for a missing directory, got %v, %v", empty.Locales(), err)
+	}
+
+	_, err = Load(fstest.MapFS{"lang/en.json": {Data: []byte(`{"bad": 1}`)}}, "lang", "en")
+	if err == nil {
+		t.Error("expected an error for a non-string message")
+	}
+}
+
+func TestTranslator_T(t *testing.T) {
+	tr, err := Load(testCatalogs, "lang", "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		locale string
+		key    string
+		args   []interface{}
+		want   string
+	}{
+		{"placeholder", "en", "welcome", []interface{}{"name", "Ana"}, "Welcome, Ana!"},
+		{"translated", "pt-BR", "welcome", []interface{}{"name", "Ana"}, "Bem-vindo, Ana!"},
+		{"default locale fallback", "pl", "welcome", []interface{}{"name", "Ana"}, "Welcome, Ana!"},
+		{"parent locale fallback", "en-GB", "welcome", []interface{}{"name", "Ana"}, "Welcome, Ana!"},
+		{"missing key", "en", "missing.key", nil, "missing.key"},
+		{"zero", "en", "cart.items", []interface{}{"count", 0}, "Your cart is empty."},
+		{"one", "en", "cart.items", []interface{}{"count", 1}, "1 item"},
+		{"other", "en", "cart.items", []interface{}{"count", 5}, "5 items"},
+		{"no zero form", "pt-BR", "cart.items", []interface{}{"count", 2}, "2 itens"},
+		{"few", "pl", "files", []interface{}{"count", 3}, "3 pliki"},
+		{"many", "pl", "files", []interface{}{"count", 5}, "5 plików"},
+		{"string count", "en", "cart.items", []interface{}{"count", "1"}, "1 item"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tr.T(tt.locale, tt.key, tt.args...); got != tt.want {
+				t.Errorf("T(%q, %q) = %q, want %q", tt.locale, tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTranslator_Match(t *testing.T) {
+	tr, err := Load(testCatalogs, "lang", "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"pt-BR,pt;q=0.9,en;q=0.8", "pt-BR"},
+		{"pl", "pl"},
+		{"de-DE,de;q=0.9", "en"},
+		{"", "en"},
+		{"en-US,en;q=0.9", "en"},
+	}
+	for _, tt := range tests {
+		if got := tr.Match(tt.header); got != tt.want {
+			t.Errorf("Match(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+
+	if locale, ok := tr.Supported("pt-br"); !ok || locale != "pt-BR" {
+		t.Errorf("Supported(\"pt-br\") = %q, %v", locale, ok)
+	}
+	if _, ok := tr.Supported("de"); ok {
+		t.Error("Supported(\"de\") should be false")
+	}
+}
+
+func TestLocaleContext(t *testing.T) {
+	ctx := WithLocale(context.Background(), "pl")
+	if got := LocaleFrom(ctx); got != "pl" {
+		t.Errorf("LocaleFrom() = %q, want %q", got, "pl")
+	}
+	if got := LocaleFrom(context.Background()); got != "" {
+		t.Errorf("LocaleFrom() without locale = %q, want empty", got)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	if got := Format("{a} and {b} and {c}", "a", 1, "b", "two"); got != "1 and two and {c}" {
+		t.Errorf("Format() = %q", got)
+	}
+}
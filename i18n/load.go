@@ -0,0 +1,64 @@
+package i18n
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Load reads every *.json, *.yaml and *.yml catalog in dir of fsys into a new
+// Translator. The file name without its extension is the locale, and files for
+// the same locale are merged. A missing dir yields a Translator without messages.
+//
+// Example:
+//
+//	tr, err := i18n.Load(os.DirFS(rootPath), "lang", "en")
+func Load(fsys fs.FS, dir, defaultLocale string) (*Translator, error) {
+	tr := New(defaultLocale)
+
+	entries, err := fs.ReadDir(fsys, dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return tr, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		ext := path.Ext(entry.Name())
+		var unmarshal func([]byte, interface{}) error
+		switch strings.ToLower(ext) {
+		case ".json":
+			unmarshal = json.Unmarshal
+		case ".yaml", ".yml":
+			unmarshal = yaml.Unmarshal
+		default:
+			continue
+		}
+
+		b, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		messages := make(map[string]interface{})
+		if err := unmarshal(b, &messages); err != nil {
+			return nil, fmt.Errorf("i18n: %s: %w", entry.Name(), err)
+		}
+
+		if err := tr.AddMessages(strings.TrimSuffix(entry.Name(), ext), messages); err != nil {
+			return nil, err
+		}
+	}
+
+	return tr, nil
+}
@@ -1,7 +1,16 @@
 package devify
 
-import "net/http"
+import (
+	"net/http"
 
+	"github.com/jorgeSader/devify/session"
+)
+
+// SessionLoad loads and saves the session on every request. The cookie session
+// store keeps the session in the cookie itself, so it needs its own middleware.
 func (d *Devify) SessionLoad(next http.Handler) http.Handler {
+	if cookieStore, ok := d.Session.Store.(*session.CookieStore); ok {
+		return cookieStore.LoadAndSave(d.Session, next)
+	}
 	return d.Session.LoadAndSave(next)
 }
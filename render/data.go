@@ -0,0 +1,134 @@
+package render
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/CloudyKit/jet/v6"
+)
+
+// DataProvider adds request-specific values, such as the current user, to the
+// data of every render. r is nil when rendering without a request (Execute,
+// String). Values that have no TemplateData field go in td.Data, which is
+// never nil here.
+type DataProvider func(r *http.Request, td *TemplateData)
+
+// AddDataProvider registers p to run after the default data is filled in, in
+// the order providers were added. Register providers while setting up the
+// application, before pages are rendered.
+//
+// Example:
+//
+//	d.Render.AddDataProvider(func(r *http.Request, td *render.TemplateData) {
+//	    if r != nil {
+//	        td.Data["user"] = currentUser(r)
+//	    }
+//	})
+func (d *Render) AddDataProvider(p DataProvider) {
+	d.providers = append(d.providers, p)
+}
+
+var templateDataType = reflect.TypeOf(TemplateData{})
+
+// prepareData fills in the default data and returns the value to execute the
+// template with. data may be:
+//
+//   - nil or *TemplateData;
+//   - a struct, or pointer to struct, embedding TemplateData or *TemplateData,
+//     whose embedded fields are filled in;
+//   - any other struct or pointer to struct, used as is;
+//   - a map[string]interface{}, copied with the TemplateData fields (Flash,
+//     CSRFToken, ...) added under their names unless already set.
+func (d *Render) prepareData(data interface{}, r *http.Request) (interface{}, error) {
+	switch v := data.(type) {
+	case nil:
+		return d.defaultData(&TemplateData{}, r), nil
+	case *TemplateData:
+		if v == nil {
+			v = &TemplateData{}
+		}
+		return d.defaultData(v, r), nil
+	case TemplateData:
+		return d.defaultData(&v, r), nil
+	case map[string]interface{}:
+		return d.mergeDefaults(v, r), nil
+	}
+
+	rv := reflect.ValueOf(data)
+	switch {
+	case rv.Kind() == reflect.Pointer && rv.IsNil():
+		return nil, fmt.Errorf("render: template data is a nil %T", data)
+	case rv.Kind() == reflect.Pointer && rv.Elem().Kind() == reflect.Struct:
+		d.fillEmbedded(rv.Elem(), r)
+		return data, nil
+	case rv.Kind() == reflect.Struct:
+		// Copy the struct so its embedded TemplateData can be filled in.
+		ptr := reflect.New(rv.Type())
+		ptr.Elem().Set(rv)
+		d.fillEmbedded(ptr.Elem(), r)
+		return ptr.Interface(), nil
+	default:
+		return nil, fmt.Errorf("render: unsupported template data type %T; use *render.TemplateData, a struct embedding render.TemplateData or a map[string]interface{}", data)
+	}
+}
+
+// fillEmbedded fills in the default data of the TemplateData embedded in the
+// addressable struct v, if any.
+func (d *Render) fillEmbedded(v reflect.Value, r *http.Request) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.Anonymous {
+			continue
+		}
+
+		switch field.Type {
+		case templateDataType:
+			d.defaultData(v.Field(i).Addr().Interface().(*TemplateData), r)
+			return
+		case reflect.PointerTo(templateDataType):
+			if v.Field(i).IsNil() {
+				v.Field(i).Set(reflect.New(templateDataType))
+			}
+			d.defaultData(v.Field(i).Interface().(*TemplateData), r)
+			return
+		}
+	}
+}
+
+// mergeDefaults returns a copy of m with the default TemplateData fields added.
+func (d *Render) mergeDefaults(m map[string]interface{}, r *http.Request) map[string]interface{} {
+	td := d.defaultData(&TemplateData{}, r)
+	tv := reflect.ValueOf(td).Elem()
+
+	merged := make(map[string]interface{}, len(m)+tv.NumField())
+	for i := 0; i < tv.NumField(); i++ {
+		merged[tv.Type().Field(i).Name] = tv.Field(i).Interface()
+	}
+	for key, value := range m {
+		merged[key] = value
+	}
+	return merged
+}
+
+// jetVars returns variables as a jet.VarMap. It accepts nil, a jet.VarMap or a
+// map[string]interface{}.
+func jetVars(variables interface{}) (jet.VarMap, error) {
+	switch v := variables.(type) {
+	case nil:
+		return make(jet.VarMap), nil
+	case jet.VarMap:
+		if v == nil {
+			return make(jet.VarMap), nil
+		}
+		return v, nil
+	case map[string]interface{}:
+		vars := make(jet.VarMap, len(v))
+		for name, value := range v {
+			vars.Set(name, value)
+		}
+		return vars, nil
+	default:
+		return nil, fmt.Errorf("render: unsupported Jet variables type %T; use jet.VarMap or map[string]interface{}", variables)
+	}
+}
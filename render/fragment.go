@@ -0,0 +1,104 @@
+package render
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// jetIdentifier matches the names Jet accepts for blocks.
+var jetIdentifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Blocks rendered by Fragment when none is named: the {{define "content"}} of a
+// Go page and the {{ block pageContent() }} of a Jet view ("content" is a
+// reserved word in Jet).
+const (
+	DefaultGoBlock  = "content"
+	DefaultJetBlock = "pageContent"
+)
+
+// Fragment renders only the named block of view, without its layout, for
+// partial page updates such as HTMX requests. An empty block selects
+// DefaultGoBlock or DefaultJetBlock. In Go templates block is any
+// {{define}} or {{block}} visible to the page; in Jet it is a {{block}} of the
+// view. r may be nil.
+//
+// Example:
+//
+//	err := d.Render.Fragment(w, r, "users/index", "user-rows", data, nil)
+func (d *Render) Fragment(w io.Writer, r *http.Request, view, block string, data, variables interface{}) error {
+	switch strings.ToLower(d.Renderer) {
+	case "go":
+		if block == "" {
+			block = DefaultGoBlock
+		}
+		return d.goFragment(w, r, view, block, data)
+	case "jet":
+		if block == "" {
+			block = DefaultJetBlock
+		}
+		return d.jetFragment(w, r, view, block, data, variables)
+	default:
+		return errors.New("no rendering engine specified")
+	}
+}
+
+func (d *Render) goFragment(w io.Writer, r *http.Request, view, block string, data interface{}) error {
+	tmpl, err := d.goTemplate(view)
+	if err != nil {
+		return err
+	}
+	if tmpl.Lookup(block) == nil {
+		return fmt.Errorf("template %s.page.tmpl has no block %q", view, block)
+	}
+
+	td, err := d.prepareData(data, r)
+	if err != nil {
+		return err
+	}
+
+	buf := new(bytes.Buffer)
+	if err := tmpl.ExecuteTemplate(buf, block, td); err != nil {
+		log.Printf("Error executing template block: %v", err)
+		return err
+	}
+
+	return d.writeOutput(w, r, buf)
+}
+
+// jetFragment imports the view into a throwaway template that only yields block.
+func (d *Render) jetFragment(w io.Writer, r *http.Request, view, block string, data, variables interface{}) error {
+	vars, err := jetVars(variables)
+	if err != nil {
+		return err
+	}
+
+	td, err := d.prepareData(data, r)
+	if err != nil {
+		return err
+	}
+
+	if !jetIdentifier.MatchString(block) {
+		return fmt.Errorf("invalid Jet block name %q", block)
+	}
+
+	viewPath := "/" + strings.TrimPrefix(view, "/") + ".jet"
+	source := fmt.Sprintf("{{ import %q }}{{ yield %s() }}", viewPath, block)
+	jt, err := d.JetViews.Parse(fmt.Sprintf("/%s.%s.fragment.jet", strings.TrimPrefix(view, "/"), block), source)
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	buf := new(bytes.Buffer)
+	if err := jt.Execute(buf, vars, td); err != nil {
+		log.Println(err)
+		return err
+	}
+	return d.writeOutput(w, r, buf)
+}
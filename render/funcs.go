@@ -0,0 +1,126 @@
+package render
+
+import (
+	"encoding/json"
+	"html/template"
+	"strings"
+	"time"
+)
+
+// DefaultFuncs returns the helpers available in every Go template and registered
+// as Jet globals:
+//
+//	formatDate t "2006-01-02"   formats a time.Time with a Go layout
+//	asset "css/app.css"          returns the public URL of a file in public/, fingerprinted
+//	                             through Render.Manifest when rendered by a Render
+//	csrfField .CSRFToken         renders the hidden CSRF token input
+//	safeHTML s                   marks s as trusted HTML
+//	json v                       encodes v as JSON
+//	upper s / lower s            changes the case of s
+//	truncate s 20                shortens s to at most n runes, adding "…"
+//
+// Jet escapes template.HTML like any other string, so HTML helpers must be piped
+// through raw there, e.g. {{ csrfField(.CSRFToken) | raw }}.
+//
+// Renderers also provide liveReload, which renders the live reload script when
+// Render.LiveReload is enabled and nothing otherwise (place it before </body>),
+// and t, which translates a key through Render.Translator:
+//
+//	{{ t .Locale "cart.items" "count" .Count }}   (Go)
+//	{{ t(.Locale, "cart.items", "count", .Count) }} (Jet)
+func DefaultFuncs() template.FuncMap {
+	return template.FuncMap{
+		"formatDate": formatDate,
+		"asset":      asset,
+		"csrfField":  csrfField,
+		"safeHTML":   safeHTML,
+		"json":       toJSON,
+		"upper":      strings.ToUpper,
+		"lower":      strings.ToLower,
+		"truncate":   truncate,
+	}
+}
+
+// AddFunc registers fn under name for Go templates and, when a Jet set is
+// configured, as a Jet global. Functions added here override the defaults.
+func (d *Render) AddFunc(name string, fn interface{}) {
+	if d.FuncMap == nil {
+		d.FuncMap = template.FuncMap{}
+	}
+	d.FuncMap[name] = fn
+	if d.JetViews != nil {
+		d.JetViews.AddGlobal(name, fn)
+	}
+}
+
+// RegisterJetGlobals adds DefaultFuncs and FuncMap to the Jet set so that both
+// engines share the same helpers. It is called once when the renderer is created.
+func (d *Render) RegisterJetGlobals() {
+	if d.JetViews == nil {
+		return
+	}
+	for name, fn := range d.funcs() {
+		d.JetViews.AddGlobal(name, fn)
+	}
+}
+
+// funcs returns DefaultFuncs and the renderer helpers merged with the user
+// registered FuncMap.
+func (d *Render) funcs() template.FuncMap {
+	fm := DefaultFuncs()
+	fm["liveReload"] = d.liveReload
+	fm["asset"] = d.asset
+	fm["t"] = d.translate
+	for name, fn := range d.FuncMap {
+		fm[name] = fn
+	}
+	return fm
+}
+
+func formatDate(t time.Time, layout string) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(layout)
+}
+
+func asset(path string) string {
+	return "/public/" + strings.TrimPrefix(path, "/")
+}
+
+// asset resolves path through the manifest, e.g. "/public/css/app.3f2a9c1d04be.css".
+func (d *Render) asset(path string) string {
+	return asset(d.Manifest.Path(path))
+}
+
+// translate returns the translation of key, or key when there is no Translator.
+func (d *Render) translate(locale, key string, args ...interface{}) string {
+	if d.Translator == nil {
+		return key
+	}
+	return d.Translator.T(locale, key, args...)
+}
+
+func csrfField(token string) template.HTML {
+	return template.HTML(`<input type="hidden" name="csrf_token" value="` + template.HTMLEscapeString(token) + `">`)
+}
+
+func safeHTML(s string) template.HTML {
+	return template.HTML(s)
+}
+
+func toJSON(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if n < 0 || len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "…"
+}
@@ -0,0 +1,45 @@
+package render
+
+import (
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/CloudyKit/jet/v6"
+)
+
+// fsLoader is a jet.Loader reading templates from a directory of an fs.FS.
+type fsLoader struct {
+	fsys fs.FS
+	dir  string
+}
+
+// NewJetLoader returns a jet.Loader that reads templates from dir in fsys, so a
+// Jet set can load views embedded in the binary.
+//
+// Example:
+//
+//	//go:embed views
+//	var files embed.FS
+//
+//	set := jet.NewSet(render.NewJetLoader(files, "views"))
+func NewJetLoader(fsys fs.FS, dir string) jet.Loader {
+	return &fsLoader{fsys: fsys, dir: dir}
+}
+
+// name maps a Jet template path such as "/home.jet" to a path in the file system.
+func (l *fsLoader) name(templatePath string) string {
+	return path.Join(l.dir, strings.TrimPrefix(path.Clean("/"+templatePath), "/"))
+}
+
+// Exists implements jet.Loader.
+func (l *fsLoader) Exists(templatePath string) bool {
+	info, err := fs.Stat(l.fsys, l.name(templatePath))
+	return err == nil && !info.IsDir()
+}
+
+// Open implements jet.Loader.
+func (l *fsLoader) Open(templatePath string) (io.ReadCloser, error) {
+	return l.fsys.Open(l.name(templatePath))
+}
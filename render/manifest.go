@@ -0,0 +1,53 @@
+package render
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// ManifestFile is the asset manifest written to public/ by "devify assets build".
+const ManifestFile = "manifest.json"
+
+// Manifest maps asset paths relative to public/ (e.g., "css/app.css") to their
+// fingerprinted names (e.g., "css/app.3f2a9c1d04be.css").
+type Manifest map[string]string
+
+// ReadManifest reads ManifestFile from the public directory fsys. A missing
+// manifest is not an error and yields an empty Manifest.
+func ReadManifest(fsys fs.FS) (Manifest, error) {
+	b, err := fs.ReadFile(fsys, ManifestFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Manifest{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Path returns the fingerprinted name of the asset, or the asset itself when it
+// is not in the manifest.
+func (m Manifest) Path(asset string) string {
+	asset = strings.TrimPrefix(path.Clean("/"+asset), "/")
+	if fingerprinted, ok := m[asset]; ok {
+		return fingerprinted
+	}
+	return asset
+}
+
+// Fingerprinted returns the set of fingerprinted names in the manifest.
+func (m Manifest) Fingerprinted() map[string]struct{} {
+	set := make(map[string]struct{}, len(m))
+	for _, name := range m {
+		set[name] = struct{}{}
+	}
+	return set
+}
@@ -2,16 +2,25 @@ package render
 
 import (
 	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/gob"
 	"errors"
 	"fmt"
 	"html/template"
+	"io"
+	"io/fs"
 	"log"
 	"net/http"
-	"path/filepath"
+	"net/url"
+	"os"
+	"path"
 	"strings"
+	"sync"
 
 	"github.com/CloudyKit/jet/v6"
 	"github.com/alexedwards/scs/v2"
+	"github.com/jorgeSader/devify/i18n"
 )
 
 type Render struct {
@@ -22,20 +31,81 @@ type Render struct {
 	ServerName    string
 	JetViews      *jet.Set
 	Session       *scs.SessionManager
-	TemplateCache map[string]*template.Template
+	TemplateCache map[string]*template.Template // guarded by mu once the renderer is in use; see SetTemplateCache
 	UseCache      bool
+	FuncMap       template.FuncMap // extra template functions; see AddFunc
+	LiveReload    bool             // inject the live reload script and serve LiveReloadHandler; see Watch
+	FS            fs.FS            // files holding views/, e.g. an embed.FS; defaults to the RootPath directory
+	Manifest      Manifest         // fingerprinted asset names used by the asset helper; see ReadManifest
+	Translator    *i18n.Translator // catalogs used by the t helper
+	// CSRFToken returns the CSRF token of the session of r, creating it. It is
+	// only called when a rendered page contains .CSRFToken and the session has
+	// no token yet, so pages without forms don't start a session.
+	CSRFToken func(r *http.Request) string
+
+	mu        sync.RWMutex
+	reloadMu  sync.Mutex
+	clients   map[chan struct{}]struct{}
+	providers []DataProvider
+}
+
+// Session keys used to carry flash messages and old form input across a redirect.
+// They are popped from the session by defaultData on the next render.
+const (
+	FlashKey            = "flash"
+	ErrorKey            = "error"
+	WarningKey          = "warning"
+	OldInputKey         = "old_input"
+	ValidationErrorsKey = "validation_errors"
+)
+
+// CSRFTokenKey is the session key holding the CSRF token copied into TemplateData.CSRFToken.
+const CSRFTokenKey = "csrf_token"
+
+// csrfPlaceholder stands in for TemplateData.CSRFToken while a page is rendered
+// for a session without a token. Output containing it gets a token created by
+// Render.CSRFToken. Like a token it is base64url, so escaping never changes it.
+var csrfPlaceholder = func() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return "csrf-" + base64.RawURLEncoding.EncodeToString(b)
+}()
+
+func init() {
+	// Session values are gob encoded, so the map types stored by Devify must be registered.
+	gob.Register(url.Values{})
+	gob.Register(map[string]string{})
 }
 
 type TemplateData struct {
-	IsAuthenticated bool
-	IntMap          map[string]int
-	StringMap       map[string]string
-	FloatMap        map[string]float64
-	Data            map[string]interface{}
-	CSRFToken       string
-	Port            string
-	ServerName      string
-	Secure          bool
+	IsAuthenticated  bool
+	IntMap           map[string]int
+	StringMap        map[string]string
+	FloatMap         map[string]float64
+	Data             map[string]interface{}
+	CSRFToken        string // a placeholder while rendering for a session without a token; see Render.CSRFToken
+	Port             string
+	ServerName       string
+	Secure           bool
+	Flash            string
+	Error            string
+	Warning          string
+	OldInput         url.Values
+	ValidationErrors map[string]string
+	Locale           string // locale of the request, used by the t helper
+}
+
+// Old returns the value submitted for field by the previous, failed form post,
+// so a form can be re-populated after a redirect.
+func (td *TemplateData) Old(field string) string {
+	return td.OldInput.Get(field)
+}
+
+// FieldError returns the validation error for field from the previous form post, if any.
+func (td *TemplateData) FieldError(field string) string {
+	return td.ValidationErrors[field]
 }
 
 func (d *Render) defaultData(td *TemplateData, r *http.Request) *TemplateData {
@@ -43,15 +113,77 @@ func (d *Render) defaultData(td *TemplateData, r *http.Request) *TemplateData {
 	td.ServerName = d.ServerName
 	td.Port = d.Port
 
+	if td.Locale == "" && r != nil {
+		td.Locale = i18n.LocaleFrom(r.Context())
+	}
+	if td.Locale == "" && d.Translator != nil {
+		td.Locale = d.Translator.DefaultLocale()
+	}
+
 	if d.Session != nil && r != nil {
-		if d.Session.Exists(r.Context(), "userID") {
+		ctx := r.Context()
+		if d.Session.Exists(ctx, "userID") {
 			td.IsAuthenticated = true
 		}
+
+		if td.CSRFToken == "" {
+			td.CSRFToken = d.Session.GetString(ctx, CSRFTokenKey)
+		}
+		if td.CSRFToken == "" && d.CSRFToken != nil {
+			td.CSRFToken = csrfPlaceholder
+		}
+
+		td.Flash = d.Session.PopString(ctx, FlashKey)
+		td.Error = d.Session.PopString(ctx, ErrorKey)
+		td.Warning = d.Session.PopString(ctx, WarningKey)
+		if old, ok := d.Session.Pop(ctx, OldInputKey).(url.Values); ok {
+			td.OldInput = old
+		}
+		if errs, ok := d.Session.Pop(ctx, ValidationErrorsKey).(map[string]string); ok {
+			td.ValidationErrors = errs
+		}
+	}
+
+	if len(d.providers) > 0 && td.Data == nil {
+		td.Data = make(map[string]interface{})
+	}
+	for _, provide := range d.providers {
+		provide(r, td)
 	}
 	return td
 }
 
 func (d *Render) Page(w http.ResponseWriter, r *http.Request, view string, data, variables interface{}) error {
+	return d.render(w, r, view, data, variables)
+}
+
+// Execute renders view with the configured engine to w, without an HTTP request.
+// Session-derived defaults (authentication, flash messages) are left empty.
+// It is meant for email bodies, PDFs and tests.
+//
+// Example:
+//
+//	var body bytes.Buffer
+//	err := d.Render.Execute(&body, "emails/welcome", &render.TemplateData{StringMap: map[string]string{"name": "Alice"}}, nil)
+func (d *Render) Execute(w io.Writer, view string, data, variables interface{}) error {
+	return d.render(w, nil, view, data, variables)
+}
+
+// String renders view with the configured engine and returns the output, without an HTTP request.
+//
+// Example:
+//
+//	html, err := d.Render.String("emails/welcome", data, nil)
+func (d *Render) String(view string, data, variables interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := d.Execute(&buf, view, data, variables); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// render dispatches to the configured engine. r may be nil.
+func (d *Render) render(w io.Writer, r *http.Request, view string, data, variables interface{}) error {
 	switch strings.ToLower(d.Renderer) {
 	case "go":
 		return d.GoPage(w, r, view, data)
@@ -62,32 +194,17 @@ func (d *Render) Page(w http.ResponseWriter, r *http.Request, view string, data,
 	}
 }
 
-// GoPage renders a standard Go template using the pre-cached template
-func (d *Render) GoPage(w http.ResponseWriter, r *http.Request, view string, data interface{}) error {
-	var tc map[string]*template.Template
-	var err error
-
-	if d.UseCache {
-		tc = d.TemplateCache
-	} else {
-		tc, err = d.CreateTemplateCache()
-		if err != nil {
-			log.Printf("Error creating template cache: %v", err)
-			return err
-		}
-	}
-
-	// Get requested template from cache
-	tmpl, ok := tc[view+".page.tmpl"]
-	if !ok {
-		return fmt.Errorf("can't get template %s.page.tmpl from cache", view)
+// GoPage renders a standard Go template using the pre-cached template. r may be nil.
+func (d *Render) GoPage(w io.Writer, r *http.Request, view string, data interface{}) error {
+	tmpl, err := d.goTemplate(view)
+	if err != nil {
+		return err
 	}
 
-	td := &TemplateData{}
-	if data != nil {
-		td = data.(*TemplateData)
+	td, err := d.prepareData(data, r)
+	if err != nil {
+		return err
 	}
-	td = d.defaultData(td, r)
 
 	buf := new(bytes.Buffer)
 	err = tmpl.Execute(buf, td)
@@ -96,30 +213,65 @@ func (d *Render) GoPage(w http.ResponseWriter, r *http.Request, view string, dat
 		return err
 	}
 
-	_, err = buf.WriteTo(w)
+	err = d.writeOutput(w, r, buf)
 	if err != nil {
-		log.Printf("Error writing template to browser: %v", err)
+		log.Printf("Error writing template output: %v", err)
 		return err
 	}
 
 	return nil
 }
 
-// JetPage renders a template using the Jet templating engine
-func (d *Render) JetPage(w http.ResponseWriter, r *http.Request, templateName string, data, variables interface{}) error {
-	var vars jet.VarMap
-	if variables == nil {
-		vars = make(jet.VarMap)
+// Exists reports whether view can be rendered by the configured engine, e.g.,
+// to fall back to a default when an optional template is missing.
+func (d *Render) Exists(view string) bool {
+	switch strings.ToLower(d.Renderer) {
+	case "go":
+		_, err := d.goTemplate(view)
+		return err == nil
+	case "jet":
+		if d.JetViews == nil {
+			return false
+		}
+		_, err := d.JetViews.GetTemplate(view + ".jet")
+		return err == nil
+	default:
+		return false
+	}
+}
+
+// goTemplate returns the parsed page for view, from the cache when UseCache is set.
+func (d *Render) goTemplate(view string) (*template.Template, error) {
+	var tmpl *template.Template
+	var ok bool
+
+	if d.UseCache {
+		tmpl, ok = d.cachedTemplate(view + ".page.tmpl")
 	} else {
-		vars = variables.(jet.VarMap)
+		tc, err := d.CreateTemplateCache()
+		if err != nil {
+			log.Printf("Error creating template cache: %v", err)
+			return nil, err
+		}
+		tmpl, ok = tc[view+".page.tmpl"]
 	}
+	if !ok {
+		return nil, fmt.Errorf("can't get template %s.page.tmpl from cache", view)
+	}
+	return tmpl, nil
+}
 
-	td := &TemplateData{}
-	if data != nil {
-		td = data.(*TemplateData)
+// JetPage renders a template using the Jet templating engine. r may be nil.
+func (d *Render) JetPage(w io.Writer, r *http.Request, templateName string, data, variables interface{}) error {
+	vars, err := jetVars(variables)
+	if err != nil {
+		return err
 	}
 
-	td = d.defaultData(td, r)
+	td, err := d.prepareData(data, r)
+	if err != nil {
+		return err
+	}
 
 	jt, err := d.JetViews.GetTemplate(fmt.Sprintf("%s.jet", templateName))
 	if err != nil {
@@ -127,48 +279,141 @@ func (d *Render) JetPage(w http.ResponseWriter, r *http.Request, templateName st
 		return err
 	}
 
-	err = jt.Execute(w, vars, td)
+	buf := new(bytes.Buffer)
+	err = jt.Execute(buf, vars, td)
 	if err != nil {
 		log.Println(err)
 		return err
 	}
 
-	return nil
+	return d.writeOutput(w, r, buf)
 }
 
-// CreateTemplateCache initializes a template cache by parsing all template files
+// writeOutput writes rendered output to w, replacing the CSRF placeholder with
+// the token of the session, which is created now if needed.
+func (d *Render) writeOutput(w io.Writer, r *http.Request, buf *bytes.Buffer) error {
+	if r != nil && d.CSRFToken != nil && bytes.Contains(buf.Bytes(), []byte(csrfPlaceholder)) {
+		out := bytes.ReplaceAll(buf.Bytes(), []byte(csrfPlaceholder), []byte(d.CSRFToken(r)))
+		_, err := w.Write(out)
+		return err
+	}
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// CreateTemplateCache initializes a template cache by parsing all template files.
+//
+// Pages are any *.page.tmpl file under views/, including subdirectories, and are
+// keyed by their path relative to views/ (e.g., "admin/users/index.page.tmpl",
+// rendered as "admin/users/index"). Every page is parsed together with all
+// layouts (views/layouts/**/*.layout.tmpl) and partials
+// (views/partials/**/*.partial.tmpl), so layouts can extend other layouts and
+// any page can include any partial by its defined name.
+//
+// Templates are read from FS when it is set, or from RootPath otherwise.
 func (d *Render) CreateTemplateCache() (map[string]*template.Template, error) {
 	templateCache := make(map[string]*template.Template)
+	fsys := d.filesystem()
 
-	pathToPages := fmt.Sprintf("%s/views/*.page.tmpl", d.RootPath)
-	pathToLayouts := fmt.Sprintf("%s/views/layouts/*.layout.tmpl", d.RootPath)
-
-	pages, err := filepath.Glob(pathToPages)
+	pages, shared, err := findTemplates(fsys)
 	if err != nil {
 		return templateCache, err
 	}
 
+	funcs := d.funcs()
 	for _, page := range pages {
-		name := filepath.Base(page)
-		ts, err := template.New(name).ParseFiles(page)
+		name, ts, err := parsePage(fsys, page, shared, funcs)
 		if err != nil {
 			return templateCache, err
 		}
+		templateCache[name] = ts
+	}
 
-		layouts, err := filepath.Glob(pathToLayouts)
-		if err != nil {
-			return templateCache, err
-		}
+	return templateCache, nil
+}
+
+// SetTemplateCache replaces the template cache. It is safe to call while pages
+// are being rendered.
+func (d *Render) SetTemplateCache(tc map[string]*template.Template) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.TemplateCache = tc
+}
+
+// cachedTemplate looks up name in the template cache.
+func (d *Render) cachedTemplate(name string) (*template.Template, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	tmpl, ok := d.TemplateCache[name]
+	return tmpl, ok
+}
+
+// viewsDir is the directory holding the templates, relative to the root of the file system.
+const viewsDir = "views"
+
+// filesystem returns FS, or the RootPath directory when FS is not set.
+func (d *Render) filesystem() fs.FS {
+	if d.FS != nil {
+		return d.FS
+	}
+	return os.DirFS(d.rootPath())
+}
 
-		if len(layouts) > 0 {
-			ts, err = ts.ParseGlob(pathToLayouts)
-			if err != nil {
-				return templateCache, err
+func (d *Render) rootPath() string {
+	if d.RootPath == "" {
+		return "."
+	}
+	return d.RootPath
+}
+
+// findTemplates walks views/ in fsys and returns its page templates and the
+// layouts and partials shared by every page. A missing directory yields no templates.
+func findTemplates(fsys fs.FS) (pages, shared []string, err error) {
+	err = fs.WalkDir(fsys, viewsDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if path == viewsDir && errors.Is(err, fs.ErrNotExist) {
+				return fs.SkipAll
 			}
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		switch {
+		case isPage(path):
+			pages = append(pages, path)
+		case isShared(path):
+			shared = append(shared, path)
 		}
+		return nil
+	})
+	return pages, shared, err
+}
 
-		templateCache[name] = ts
+// parsePage parses page together with the shared templates and returns it with
+// its cache key, the path relative to views/.
+func parsePage(fsys fs.FS, page string, shared []string, funcs template.FuncMap) (string, *template.Template, error) {
+	name := strings.TrimPrefix(page, viewsDir+"/")
+
+	ts, err := template.New(path.Base(page)).Funcs(funcs).ParseFS(fsys, page)
+	if err != nil {
+		return "", nil, err
 	}
 
-	return templateCache, nil
+	if len(shared) > 0 {
+		ts, err = ts.ParseFS(fsys, shared...)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	return name, ts, nil
+}
+
+func isPage(name string) bool {
+	return strings.HasSuffix(name, ".page.tmpl")
+}
+
+func isShared(name string) bool {
+	return strings.HasSuffix(name, ".layout.tmpl") || strings.HasSuffix(name, ".partial.tmpl")
 }
@@ -1,7 +1,18 @@
 package render
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
+	"testing/fstest"
+
+	"github.com/CloudyKit/jet/v6"
+	"github.com/jorgeSader/devify/i18n"
 )
 
 var pageData = []struct {
@@ -64,3 +75,388 @@ func TestRender_JetPage(t *testing.T) {
 		t.Error("Error rendering non-existent jet template", err)
 	}
 }
+
+func TestRender_DefaultDataFlash(t *testing.T) {
+	r, _ := setupTestRequest(t)
+	ctx := r.Context()
+
+	testRenderer.Session.Put(ctx, FlashKey, "saved")
+	testRenderer.Session.Put(ctx, ErrorKey, "failed")
+	testRenderer.Session.Put(ctx, OldInputKey, url.Values{"email": {"a@example.com"}})
+	testRenderer.Session.Put(ctx, ValidationErrorsKey, map[string]string{"email": "taken"})
+
+	td := testRenderer.defaultData(&TemplateData{}, r)
+
+	if td.Flash != "saved" || td.Error != "failed" || td.Warning != "" {
+		t.Errorf("flash fields = %q, %q, %q; want \"saved\", \"failed\", \"\"", td.Flash, td.Error, td.Warning)
+	}
+	if got := td.Old("email"); got != "a@example.com" {
+		t.Errorf("Old(\"email\") = %q, want %q", got, "a@example.com")
+	}
+	if got := td.FieldError("email"); got != "taken" {
+		t.Errorf("FieldError(\"email\") = %q, want %q", got, "taken")
+	}
+
+	td = testRenderer.defaultData(&TemplateData{}, r)
+	if td.Flash != "" || td.Old("email") != "" {
+		t.Error("flash data was not removed from the session after the first render")
+	}
+}
+
+func TestRender_DefaultDataCSRFToken(t *testing.T) {
+	r, _ := setupTestRequest(t)
+	testRenderer.Session.Put(r.Context(), CSRFTokenKey, "token")
+
+	td := testRenderer.defaultData(&TemplateData{}, r)
+	if td.CSRFToken != "token" {
+		t.Errorf("CSRFToken = %q, want %q", td.CSRFToken, "token")
+	}
+
+	// The token stays in the session for the next form.
+	td = testRenderer.defaultData(&TemplateData{}, r)
+	if td.CSRFToken != "token" {
+		t.Errorf("CSRFToken on second render = %q, want %q", td.CSRFToken, "token")
+	}
+}
+
+func TestRender_LazyCSRFToken(t *testing.T) {
+	testRenderer.RootPath = "./test-data"
+	testRenderer.Renderer = "go"
+	defer func() { testRenderer.CSRFToken = nil }()
+
+	tests := []struct {
+		name       string
+		view       string
+		wantCalled bool
+	}{
+		{"page with a form", "admin/users/index", true},
+		{"page without a form", "home", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, w := setupTestRequest(t)
+			called := false
+			testRenderer.CSRFToken = func(*http.Request) string {
+				called = true
+				return "new-token"
+			}
+
+			if err := testRenderer.Page(w, r, tt.view, nil, nil); err != nil {
+				t.Fatal(err)
+			}
+			if called != tt.wantCalled {
+				t.Errorf("CSRFToken called = %v; want %v", called, tt.wantCalled)
+			}
+			if strings.Contains(w.Body.String(), csrfPlaceholder) {
+				t.Error("output contains the CSRF placeholder")
+			}
+			if tt.wantCalled && !strings.Contains(w.Body.String(), `value="new-token"`) {
+				t.Errorf("output does not contain the new token: %q", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestRender_NestedViews(t *testing.T) {
+	r, w := setupTestRequest(t)
+	testRenderer.RootPath = "./test-data"
+	testRenderer.Renderer = "go"
+
+	err := testRenderer.Page(w, r, "admin/users/index", &TemplateData{CSRFToken: "abc"}, nil)
+	if err != nil {
+		t.Fatalf("Error rendering nested go page: %v", err)
+	}
+
+	body := w.Body.String()
+	for _, want := range []string{
+		"<html><body>",
+		"<nav>MENU</nav>",
+		`<input type="hidden" name="csrf_token" value="abc">`,
+		"USERS!",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("rendered page does not contain %q; got %q", want, body)
+		}
+	}
+}
+
+func TestRender_JetGlobals(t *testing.T) {
+	testRenderer.RegisterJetGlobals()
+
+	for _, name := range []string{"formatDate", "asset", "csrfField", "shout"} {
+		if _, ok := testRenderer.JetViews.LookupGlobal(name); !ok {
+			t.Errorf("Jet global %s not registered", name)
+		}
+	}
+}
+
+func TestRender_String(t *testing.T) {
+	testRenderer.RootPath = "./test-data"
+
+	tests := []struct {
+		name     string
+		renderer string
+		view     string
+		want     string
+		wantErr  bool
+	}{
+		{"go", "go", "admin/users/index", "USERS!", false},
+		{"jet", "jet", "home", "Hello, from home.jet", false},
+		{"go missing view", "go", "non-existent", "", true},
+		{"jet missing view", "jet", "non-existent", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testRenderer.Renderer = tt.renderer
+
+			got, err := testRenderer.String(tt.view, nil, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("String() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("String() = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRender_FS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"views/layouts/base.layout.tmpl": {Data: []byte(`{{define "base"}}<main>{{block "content" .}}{{end}}</main>{{end}}`)},
+		"views/admin/home.page.tmpl":     {Data: []byte(`{{template "base" .}}{{define "content"}}embedded go{{end}}`)},
+		"views/home.jet":                 {Data: []byte(`embedded jet`)},
+	}
+
+	goRenderer := &Render{Renderer: "go", RootPath: "./does-not-exist", FS: fsys}
+	got, err := goRenderer.String("admin/home", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "<main>embedded go</main>" {
+		t.Errorf("go: expected embedded template, got %q", got)
+	}
+
+	jetRenderer := &Render{Renderer: "jet", JetViews: jet.NewSet(NewJetLoader(fsys, "views"))}
+	got, err = jetRenderer.String("home", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "embedded jet" {
+		t.Errorf("jet: expected embedded template, got %q", got)
+	}
+
+	if _, err := jetRenderer.String("../views/home", nil, nil); err == nil {
+		t.Error("jet: expected paths outside the views directory not to resolve")
+	}
+
+	if err := goRenderer.Watch(context.Background()); !errors.Is(err, ErrWatchFS) {
+		t.Errorf("expected ErrWatchFS, got %v", err)
+	}
+}
+
+func TestRender_AssetManifest(t *testing.T) {
+	fsys := fstest.MapFS{
+		ManifestFile: {Data: []byte(`{"css/app.css": "css/app.3f2a9c1d04be.css"}`)},
+	}
+	manifest, err := ReadManifest(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := &Render{Manifest: manifest}
+	assetFunc := r.funcs()["asset"].(func(string) string)
+
+	tests := []struct {
+		asset string
+		want  string
+	}{
+		{"css/app.css", "/public/css/app.3f2a9c1d04be.css"},
+		{"/css/app.css", "/public/css/app.3f2a9c1d04be.css"},
+		{"js/app.js", "/public/js/app.js"},
+	}
+	for _, tt := range tests {
+		if got := assetFunc(tt.asset); got != tt.want {
+			t.Errorf("asset(%q) = %q, want %q", tt.asset, got, tt.want)
+		}
+	}
+
+	if _, ok := manifest.Fingerprinted()["css/app.3f2a9c1d04be.css"]; !ok {
+		t.Error("expected fingerprinted name in Fingerprinted()")
+	}
+
+	empty, err := ReadManifest(fstest.MapFS{})
+	if err != nil || len(empty) != 0 {
+		t.Errorf("expected empty manifest without error, got %v, %v", empty, err)
+	}
+}
+
+func TestRender_Translate(t *testing.T) {
+	tr := i18n.New("en")
+	if err := tr.AddMessages("en", map[string]interface{}{
+		"items": map[string]interface{}{"one": "{count} item", "other": "{count} items"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tr.AddMessages("pt-BR", map[string]interface{}{
+		"items": map[string]interface{}{"one": "{count} item", "other": "{count} itens"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	fsys := fstest.MapFS{
+		"views/cart.page.tmpl": {Data: []byte(`{{ t .Locale "items" "count" 2 }}`)},
+		"views/cart.jet":       {Data: []byte(`{{ t(.Locale, "items", "count", 2) }}`)},
+	}
+
+	tests := []struct {
+		renderer string
+		locale   string
+		want     string
+	}{
+		{"go", "pt-BR", "2 itens"},
+		{"go", "", "2 items"},
+		{"jet", "pt-BR", "2 itens"},
+		{"jet", "", "2 items"},
+	}
+	for _, tt := range tests {
+		r := &Render{Renderer: tt.renderer, FS: fsys, Translator: tr, JetViews: jet.NewSet(NewJetLoader(fsys, "views"))}
+		r.RegisterJetGlobals()
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if tt.locale != "" {
+			req = req.WithContext(i18n.WithLocale(req.Context(), tt.locale))
+		}
+
+		var buf bytes.Buffer
+		if err := r.render(&buf, req, "cart", nil, nil); err != nil {
+			t.Fatalf("%s: %v", tt.renderer, err)
+		}
+		if got := buf.String(); got != tt.want {
+			t.Errorf("%s %q: got %q, want %q", tt.renderer, tt.locale, got, tt.want)
+		}
+	}
+}
+
+func TestRender_Fragment(t *testing.T) {
+	fsys := fstest.MapFS{
+		"views/layouts/base.layout.tmpl": {Data: []byte(`{{define "base"}}<html>{{block "content" .}}{{end}}</html>{{end}}`)},
+		"views/users.page.tmpl":          {Data: []byte(`{{template "base" .}}{{define "content"}}<ul>{{template "rows" .}}</ul>{{end}}{{define "rows"}}<li>{{index .StringMap "name"}}</li>{{end}}`)},
+		"views/layouts/base.jet":         {Data: []byte(`<html>{{ yield pageContent() }}</html>`)},
+		"views/users.jet":                {Data: []byte(`{{ extends "layouts/base.jet" }}{{ block pageContent() }}<ul>{{ yield rows() }}</ul>{{ end }}{{ block rows() }}<li>{{ .StringMap["name"] }}</li>{{ end }}`)},
+	}
+
+	tests := []struct {
+		name     string
+		renderer string
+		block    string
+		want     string
+		wantErr  bool
+	}{
+		{"go default block", "go", "", "<ul><li>Ana</li></ul>", false},
+		{"go named block", "go", "rows", "<li>Ana</li>", false},
+		{"go missing block", "go", "missing", "", true},
+		{"jet default block", "jet", "", "<ul><li>Ana</li></ul>", false},
+		{"jet named block", "jet", "rows", "<li>Ana</li>", false},
+		{"jet invalid block", "jet", "user-rows", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Render{Renderer: tt.renderer, FS: fsys, JetViews: jet.NewSet(NewJetLoader(fsys, "views"))}
+			data := &TemplateData{StringMap: map[string]string{"name": "Ana"}}
+
+			var buf bytes.Buffer
+			err := r.Fragment(&buf, nil, "users", tt.block, data, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Fragment() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := strings.TrimSpace(buf.String()); !tt.wantErr && got != tt.want {
+				t.Errorf("Fragment() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+type userPage struct {
+	TemplateData
+	Name string
+}
+
+type userPagePtr struct {
+	*TemplateData
+	Name string
+}
+
+type plainPage struct {
+	Name string
+}
+
+func TestRender_TypedData(t *testing.T) {
+	fsys := fstest.MapFS{
+		"views/user.page.tmpl":  {Data: []byte(`{{.Name}}|{{.ServerName}}|{{index .Data "app"}}`)},
+		"views/plain.page.tmpl": {Data: []byte(`{{.Name}}`)},
+		"views/user.jet":        {Data: []byte(`{{ .Name }}|{{ .ServerName }}|{{ .Data["app"] }}|{{ greeting }}`)},
+	}
+
+	r := &Render{Renderer: "go", FS: fsys, ServerName: "example.com", JetViews: jet.NewSet(NewJetLoader(fsys, "views"))}
+	r.AddDataProvider(func(req *http.Request, td *TemplateData) {
+		td.Data["app"] = "devify"
+	})
+
+	tests := []struct {
+		name     string
+		renderer string
+		view     string
+		data     interface{}
+		vars     interface{}
+		want     string
+		wantErr  bool
+	}{
+		{"embedded struct pointer", "go", "user", &userPage{Name: "Ana"}, nil, "Ana|example.com|devify", false},
+		{"embedded struct value", "go", "user", userPage{Name: "Ana"}, nil, "Ana|example.com|devify", false},
+		{"embedded nil pointer", "go", "user", &userPagePtr{Name: "Ana"}, nil, "Ana|example.com|devify", false},
+		{"map merged with defaults", "go", "user", map[string]interface{}{"Name": "Ana"}, nil, "Ana|example.com|devify", false},
+		{"plain struct", "go", "plain", plainPage{Name: "Ana"}, nil, "Ana", false},
+		{"unsupported data", "go", "plain", "Ana", nil, "", true},
+		{"nil pointer", "go", "plain", (*plainPage)(nil), nil, "", true},
+		{"jet map variables", "jet", "user", &userPage{Name: "Ana"}, map[string]interface{}{"greeting": "hi"}, "Ana|example.com|devify|hi", false},
+		{"jet unsupported variables", "jet", "user", nil, "greeting", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r.Renderer = tt.renderer
+
+			var buf bytes.Buffer
+			err := r.Execute(&buf, tt.view, tt.data, tt.vars)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := buf.String(); !tt.wantErr && got != tt.want {
+				t.Errorf("Execute() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRender_Exists(t *testing.T) {
+	fsys := fstest.MapFS{
+		"views/errors/404.page.tmpl": {Data: []byte(`not found`)},
+		"views/errors/404.jet":       {Data: []byte(`not found`)},
+	}
+
+	for _, renderer := range []string{"go", "jet"} {
+		r := &Render{Renderer: renderer, FS: fsys, JetViews: jet.NewSet(NewJetLoader(fsys, "views"))}
+		if !r.Exists("errors/404") {
+			t.Errorf("%s: expected errors/404 to exist", renderer)
+		}
+		if r.Exists("errors/500") {
+			t.Errorf("%s: expected errors/500 not to exist", renderer)
+		}
+	}
+
+	if (&Render{Renderer: "foo"}).Exists("errors/404") {
+		t.Error("expected no views without a rendering engine")
+	}
+}
@@ -1,12 +1,15 @@
 package render
 
 import (
-	"github.com/CloudyKit/jet/v6"
-	"github.com/alexedwards/scs/v2"
+	"html/template"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
+
+	"github.com/CloudyKit/jet/v6"
+	"github.com/alexedwards/scs/v2"
 )
 
 var views = jet.NewSet(
@@ -19,6 +22,9 @@ var testRenderer = Render{
 	RootPath: "",
 	JetViews: views,
 	Session:  scs.New(),
+	FuncMap: template.FuncMap{
+		"shout": func(s string) string { return strings.ToUpper(s) + "!" },
+	},
 }
 
 func TestMain(m *testing.M) {
@@ -0,0 +1,270 @@
+package render
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// LiveReloadPath is the route LiveReloadHandler is mounted on by Devify.
+const LiveReloadPath = "/_devify/livereload"
+
+// ErrWatchFS is returned by Watch when templates are loaded from Render.FS.
+var ErrWatchFS = errors.New("render: templates loaded from an fs.FS cannot be watched")
+
+// watchDebounce groups the bursts of events editors emit for a single save.
+const watchDebounce = 100 * time.Millisecond
+
+// liveReloadScript reloads the page when LiveReloadHandler sends a reload event,
+// or when the connection comes back after the server restarted.
+const liveReloadScript = `<script>(function(){var lost=false,es=new EventSource("` + LiveReloadPath + `");` +
+	`es.addEventListener("reload",function(){location.reload()});` +
+	`es.onerror=function(){lost=true};es.onopen=function(){if(lost){location.reload()}}})();</script>`
+
+// Watch watches views/ and its subdirectories until ctx is done. When a page
+// changes, only its TemplateCache entry is rebuilt; when a layout or partial
+// changes, every page is rebuilt since they all include it. A template that fails
+// to parse is logged and its previous version is kept. After each change,
+// browsers connected to LiveReloadHandler are told to reload.
+//
+// Watch returns once the watcher is set up. It is meant for development; Devify
+// starts it when DEBUG is true. Only views on disk can be watched, so it fails
+// when FS is set.
+//
+// Example:
+//
+//	if err := d.Render.Watch(ctx); err != nil {
+//	    log.Println(err)
+//	}
+func (d *Render) Watch(ctx context.Context) error {
+	if d.FS != nil {
+		return ErrWatchFS
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	if err := addWatchDirs(watcher, filepath.Join(d.rootPath(), viewsDir), nil); err != nil {
+		watcher.Close()
+		return err
+	}
+
+	go d.watch(ctx, watcher)
+	return nil
+}
+
+// watch collects changed paths and applies them once the events settle.
+func (d *Render) watch(ctx context.Context, watcher *fsnotify.Watcher) {
+	defer watcher.Close()
+
+	changed := make(map[string]struct{})
+	timer := time.NewTimer(watchDebounce)
+	timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					// Files may be created before the directory is watched.
+					if err := addWatchDirs(watcher, event.Name, changed); err != nil {
+						log.Printf("Error watching %s: %v", event.Name, err)
+					}
+				}
+			}
+			if event.Has(fsnotify.Chmod) && !event.Has(fsnotify.Write) {
+				continue
+			}
+			changed[event.Name] = struct{}{}
+			timer.Reset(watchDebounce)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("Error watching views: %v", err)
+		case <-timer.C:
+			d.reload(changed)
+			changed = make(map[string]struct{})
+		}
+	}
+}
+
+// reload rebuilds the template cache entries affected by the changed paths and
+// notifies live reload clients.
+func (d *Render) reload(changed map[string]struct{}) {
+	if d.UseCache {
+		if err := d.rebuildTemplates(changed); err != nil {
+			log.Printf("Error reloading templates: %v", err)
+		}
+	}
+	d.notifyReload()
+}
+
+// rebuildTemplates updates the template cache for the changed paths.
+func (d *Render) rebuildTemplates(changed map[string]struct{}) error {
+	fsys := d.filesystem()
+	_, shared, err := findTemplates(fsys)
+	if err != nil {
+		return err
+	}
+
+	for path := range changed {
+		if isShared(path) {
+			tc, err := d.CreateTemplateCache()
+			if err != nil {
+				return err
+			}
+			d.SetTemplateCache(tc)
+			return nil
+		}
+	}
+
+	funcs := d.funcs()
+	var errs []error
+	for path := range changed {
+		if !isPage(path) {
+			continue
+		}
+
+		rel, err := filepath.Rel(d.rootPath(), path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		page := filepath.ToSlash(rel)
+
+		if _, err := fs.Stat(fsys, page); errors.Is(err, fs.ErrNotExist) {
+			d.removeTemplate(strings.TrimPrefix(page, viewsDir+"/"))
+			continue
+		}
+
+		name, ts, err := parsePage(fsys, page, shared, funcs)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		d.storeTemplate(name, ts)
+	}
+	return errors.Join(errs...)
+}
+
+func (d *Render) storeTemplate(name string, ts *template.Template) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.TemplateCache == nil {
+		d.TemplateCache = make(map[string]*template.Template)
+	}
+	d.TemplateCache[name] = ts
+}
+
+func (d *Render) removeTemplate(name string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.TemplateCache, name)
+}
+
+// addWatchDirs adds root and every directory below it to watcher, recording the
+// files found in changed when it is not nil.
+func addWatchDirs(watcher *fsnotify.Watcher, root string, changed map[string]struct{}) error {
+	return filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return watcher.Add(path)
+		}
+		if changed != nil {
+			changed[path] = struct{}{}
+		}
+		return nil
+	})
+}
+
+// LiveReloadHandler streams server-sent events to the script rendered by the
+// liveReload template helper, sending a reload event whenever Watch sees a
+// change. It responds 404 unless LiveReload is true.
+func (d *Render) LiveReloadHandler(w http.ResponseWriter, r *http.Request) {
+	if !d.LiveReload {
+		http.NotFound(w, r)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	ch := d.subscribeReload()
+	defer d.unsubscribeReload(ch)
+
+	fmt.Fprint(w, ": connected\n\n")
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ch:
+			fmt.Fprint(w, "event: reload\ndata: {}\n\n")
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (d *Render) subscribeReload() chan struct{} {
+	d.reloadMu.Lock()
+	defer d.reloadMu.Unlock()
+	if d.clients == nil {
+		d.clients = make(map[chan struct{}]struct{})
+	}
+	ch := make(chan struct{}, 1)
+	d.clients[ch] = struct{}{}
+	return ch
+}
+
+func (d *Render) unsubscribeReload(ch chan struct{}) {
+	d.reloadMu.Lock()
+	defer d.reloadMu.Unlock()
+	delete(d.clients, ch)
+}
+
+// notifyReload tells every connected browser to reload, without blocking on slow clients.
+func (d *Render) notifyReload() {
+	d.reloadMu.Lock()
+	defer d.reloadMu.Unlock()
+	for ch := range d.clients {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// liveReload renders the live reload script when LiveReload is enabled.
+func (d *Render) liveReload() template.HTML {
+	if !d.LiveReload {
+		return ""
+	}
+	return template.HTML(liveReloadScript)
+}
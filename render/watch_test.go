@@ -0,0 +1,128 @@
+package render
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeView(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRender_Watch(t *testing.T) {
+	root := t.TempDir()
+	views := filepath.Join(root, "views")
+	writeView(t, filepath.Join(views, "layouts", "base.layout.tmpl"), `{{define "base"}}<main>{{block "content" .}}{{end}}</main>{{end}}`)
+	writeView(t, filepath.Join(views, "home.page.tmpl"), `{{template "base" .}}{{define "content"}}v1{{end}}`)
+	writeView(t, filepath.Join(views, "about.page.tmpl"), `{{template "base" .}}{{define "content"}}about{{end}}`)
+
+	r := &Render{Renderer: "go", RootPath: root, UseCache: true}
+	tc, err := r.CreateTemplateCache()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.SetTemplateCache(tc)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := r.Watch(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// waitFor polls until view renders want.
+	waitFor := func(view, want string) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		var got string
+		for time.Now().Before(deadline) {
+			got, _ = r.String(view, nil, nil)
+			if got == want {
+				return
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		t.Fatalf("%s: expected %q, got %q", view, want, got)
+	}
+
+	aboutBefore, _ := r.cachedTemplate("about.page.tmpl")
+
+	writeView(t, filepath.Join(views, "home.page.tmpl"), `{{template "base" .}}{{define "content"}}v2{{end}}`)
+	waitFor("home", "<main>v2</main>")
+	if aboutAfter, _ := r.cachedTemplate("about.page.tmpl"); aboutAfter != aboutBefore {
+		t.Error("changing a page rebuilt unrelated pages")
+	}
+
+	writeView(t, filepath.Join(views, "layouts", "base.layout.tmpl"), `{{define "base"}}<section>{{block "content" .}}{{end}}</section>{{end}}`)
+	waitFor("about", "<section>about</section>")
+
+	writeView(t, filepath.Join(views, "admin", "index.page.tmpl"), `{{template "base" .}}{{define "content"}}admin{{end}}`)
+	waitFor("admin/index", "<section>admin</section>")
+
+	if err := os.Remove(filepath.Join(views, "about.page.tmpl")); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, ok := r.cachedTemplate("about.page.tmpl"); !ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("removed page still in template cache")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestRender_LiveReloadHandler(t *testing.T) {
+	r := &Render{}
+
+	w := httptest.NewRecorder()
+	r.LiveReloadHandler(w, httptest.NewRequest(http.MethodGet, LiveReloadPath, nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 with live reload disabled, got %d", w.Code)
+	}
+	if r.liveReload() != "" {
+		t.Error("expected no script with live reload disabled")
+	}
+
+	r.LiveReload = true
+	if !strings.Contains(string(r.liveReload()), LiveReloadPath) {
+		t.Error("expected the script to connect to LiveReloadPath")
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(r.LiveReloadHandler))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("expected text/event-stream, got %q", ct)
+	}
+
+	lines := bufio.NewReader(resp.Body)
+	if line, _ := lines.ReadString('\n'); !strings.HasPrefix(line, ": connected") {
+		t.Fatalf("expected connected comment, got %q", line)
+	}
+	lines.ReadString('\n')
+
+	r.notifyReload()
+	if line, _ := lines.ReadString('\n'); line != "event: reload\n" {
+		t.Errorf("expected reload event, got %q", line)
+	}
+}
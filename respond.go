@@ -0,0 +1,200 @@
+package devify
+
+import (
+	"bytes"
+	"encoding/xml"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jorgeSader/devify/render"
+)
+
+// Media types offered by Respond, in order of preference for equal q-values.
+const (
+	mediaHTML = "text/html"
+	mediaJSON = "application/json"
+	mediaXML  = "application/xml"
+)
+
+var respondOffers = []string{mediaHTML, mediaJSON, mediaXML}
+
+// Respond writes data with status in the format the client asks for:
+//
+//   - HTMX requests (HX-Request header, not boosted) get only the default block
+//     of view (see render.Fragment), so it can be swapped into the page;
+//   - clients accepting JSON or XML before HTML get the payload encoded with
+//     WriteJSON or WriteXML;
+//   - everyone else, including browsers and clients without an Accept header,
+//     gets view rendered as a full page.
+//
+// API clients get the Data map of a *render.TemplateData, or any other data
+// (such as a view-model struct) encoded as is.
+//
+// Example:
+//
+//	data := &render.TemplateData{Data: map[string]interface{}{"users": users}}
+//	if err := d.Respond(w, r, http.StatusOK, "users/index", data); err != nil {
+//	    d.ServerError(w, r, err)
+//	}
+func (d *Devify) Respond(w http.ResponseWriter, r *http.Request, status int, view string, data interface{}) error {
+	w.Header().Add("Vary", "Accept")
+	w.Header().Add("Vary", "HX-Request")
+
+	if r.Header.Get("HX-Request") == "true" && r.Header.Get("HX-Boosted") != "true" {
+		var buf bytes.Buffer
+		if err := d.Render.Fragment(&buf, r, view, "", data, nil); err != nil {
+			return err
+		}
+		return writeHTML(w, status, &buf)
+	}
+
+	switch negotiate(r.Header.Get("Accept"), respondOffers) {
+	case mediaJSON:
+		return d.WriteJSON(w, status, responsePayload(data))
+	case mediaXML:
+		payload := responsePayload(data)
+		if m, ok := payload.(map[string]interface{}); ok {
+			payload = xmlDocument(m)
+		}
+		return d.WriteXML(w, status, payload)
+	default:
+		var buf bytes.Buffer
+		if err := d.Render.Page(&bufferedResponse{ResponseWriter: w, buf: &buf}, r, view, data, nil); err != nil {
+			return err
+		}
+		return writeHTML(w, status, &buf)
+	}
+}
+
+// bufferedResponse collects a rendered page so that rendering errors can still
+// be reported with a different status.
+type bufferedResponse struct {
+	http.ResponseWriter
+	buf *bytes.Buffer
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	return b.buf.Write(p)
+}
+
+func writeHTML(w http.ResponseWriter, status int, buf *bytes.Buffer) error {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// wantsJSON reports whether r is an API request preferring a JSON response.
+func wantsJSON(r *http.Request) bool {
+	return negotiate(r.Header.Get("Accept"), respondOffers) == mediaJSON ||
+		(strings.HasPrefix(r.Header.Get("Content-Type"), mediaJSON) && r.Header.Get("Accept") == "")
+}
+
+// responsePayload returns the part of data that is sent to API clients.
+func responsePayload(data interface{}) interface{} {
+	if td, ok := data.(*render.TemplateData); ok {
+		if td == nil || td.Data == nil {
+			return map[string]interface{}{}
+		}
+		return td.Data
+	}
+	return data
+}
+
+// negotiate returns the offer preferred by an Accept header, or the first offer
+// when the header is empty or accepts none of them. For equal q-values, exact
+// types beat type/*, which beats */*, and then the range listed first in the
+// header wins, so "application/json, text/html" selects JSON. Offers matched
+// by the same range keep their order.
+func negotiate(accept string, offers []string) string {
+	if strings.TrimSpace(accept) == "" {
+		return offers[0]
+	}
+
+	best, bestQ, bestSpecificity, bestPosition := offers[0], -1.0, -1, 0
+	for _, offer := range offers {
+		q, specificity, position := acceptQuality(accept, offer)
+		if q > bestQ || (q == bestQ && (specificity > bestSpecificity ||
+			(specificity == bestSpecificity && position < bestPosition))) {
+			best, bestQ, bestSpecificity, bestPosition = offer, q, specificity, position
+		}
+	}
+	if bestQ <= 0 {
+		return offers[0]
+	}
+	return best
+}
+
+// acceptQuality returns the q-value the most specific matching media range of
+// accept gives offer, how specific that range is (0 for */*, 2 for an exact
+// type) and its position in accept.
+func acceptQuality(accept, offer string) (float64, int, int) {
+	offerType, _, _ := strings.Cut(offer, "/")
+	q, specificity, position := 0.0, -1, 0
+
+	for i, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		s := -1
+		switch {
+		case mediaType == offer || (offer == mediaXML && mediaType == "text/xml"):
+			s = 2
+		case mediaType == offerType+"/*":
+			s = 1
+		case mediaType == "*/*":
+			s = 0
+		}
+		if s < specificity || s < 0 {
+			continue
+		}
+
+		value := 1.0
+		if raw, ok := params["q"]; ok {
+			if parsed, err := strconv.ParseFloat(raw, 64); err == nil {
+				value = parsed
+			}
+		}
+		q, specificity, position = value, s, i
+	}
+	return q, specificity, position
+}
+
+// xmlDocument encodes a map as a <response> root element.
+type xmlDocument map[string]interface{}
+
+func (m xmlDocument) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name = xml.Name{Local: "response"}
+	return xmlMap(m).MarshalXML(e, start)
+}
+
+// xmlMap encodes a map as one child element per key, sorted by key.
+type xmlMap map[string]interface{}
+
+func (m xmlMap) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := m[key]
+		if nested, ok := value.(map[string]interface{}); ok {
+			value = xmlMap(nested)
+		}
+		if err := e.EncodeElement(value, xml.StartElement{Name: xml.Name{Local: key}}); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
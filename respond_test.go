@@ -0,0 +1,81 @@
+package devify
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jorgeSader/devify/render"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{"", mediaHTML},
+		{"*/*", mediaHTML},
+		{"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", mediaHTML},
+		{"application/json", mediaJSON},
+		{"application/json, text/html", mediaJSON},
+		{"text/html, application/json", mediaHTML},
+		{"application/xml, application/json", mediaXML},
+		{"text/xml", mediaXML},
+		{"text/html;q=0.5, application/json", mediaJSON},
+		{"application/*, text/html", mediaHTML},
+		{"application/*", mediaJSON},
+		{"application/json;q=0, */*", mediaHTML},
+		{"image/png", mediaHTML},
+	}
+	for _, tt := range tests {
+		if got := negotiate(tt.accept, respondOffers); got != tt.want {
+			t.Errorf("negotiate(%q) = %s; want %s", tt.accept, got, tt.want)
+		}
+	}
+}
+
+func TestRespond(t *testing.T) {
+	tests := []struct {
+		name        string
+		header      map[string]string
+		contentType string
+		body        string
+	}{
+		{"browser", map[string]string{"Accept": "text/html"}, "text/html", "<html><main>Ada</main></html>"},
+		{"no Accept", nil, "text/html", "<html><main>Ada</main></html>"},
+		{"JSON", map[string]string{"Accept": "application/json, text/html"}, "application/json", `"name":"Ada"`},
+		{"XML", map[string]string{"Accept": "application/xml"}, "application/xml", "<response><name>Ada</name></response>"},
+		{"HTMX", map[string]string{"HX-Request": "true"}, "text/html", "<main>Ada</main>"},
+		{"boosted HTMX", map[string]string{"HX-Request": "true", "HX-Boosted": "true"}, "text/html", "<html><main>Ada</main></html>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newTestApp(t)
+			useViews(d, map[string]string{
+				"user.page.tmpl": `<html>{{block "content" .}}<main>{{index .Data "name"}}</main>{{end}}</html>`,
+			})
+
+			r := withSession(t, d, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+			for k, v := range tt.header {
+				r.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			data := &render.TemplateData{Data: map[string]interface{}{"name": "Ada"}}
+			if err := d.Respond(w, r, http.StatusCreated, "user", data); err != nil {
+				t.Fatal(err)
+			}
+
+			if w.Code != http.StatusCreated {
+				t.Errorf("status = %d; want %d", w.Code, http.StatusCreated)
+			}
+			if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, tt.contentType) {
+				t.Errorf("Content-Type = %q; want %s", ct, tt.contentType)
+			}
+			if body := w.Body.String(); !strings.Contains(body, tt.body) || (tt.name == "HTMX" && strings.Contains(body, "<html>")) {
+				t.Errorf("body = %q; want %q", body, tt.body)
+			}
+		})
+	}
+}
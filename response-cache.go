@@ -0,0 +1,239 @@
+package devify
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"net/http"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jorgeSader/devify/i18n"
+)
+
+// responseCachePrefix namespaces cached responses inside d.Cache.
+const responseCachePrefix = "response"
+
+// routeParam matches chi URL parameters such as {id} or {slug:[a-z-]+}.
+var routeParam = regexp.MustCompile(`\{[^}]*\}`)
+
+func init() {
+	gob.Register(cachedResponse{})
+}
+
+// ResponseCacheOptions configures the CacheResponse middleware.
+type ResponseCacheOptions struct {
+	// Vary lists request headers whose values become part of the cache key
+	// (e.g., "Accept-Language"). They are also added to the response Vary header.
+	Vary []string
+	// Methods lists the request methods that are cached. Defaults to GET and HEAD.
+	Methods []string
+	// StatusCodes lists the response codes that are cached. Defaults to 200.
+	StatusCodes []int
+	// CacheAuthenticated allows caching responses for requests whose session has
+	// a userID. It is false by default so per-user pages are never shared.
+	CacheAuthenticated bool
+}
+
+// cachedResponse is the value stored in d.Cache for a cached HTTP response.
+type cachedResponse struct {
+	Status   int
+	Header   http.Header
+	Body     []byte
+	StoredAt time.Time
+}
+
+// responseRecorder captures the status, headers and body written by a handler
+// while passing them through to the underlying writer.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rr *responseRecorder) WriteHeader(status int) {
+	if rr.status == 0 {
+		rr.status = status
+	}
+	rr.ResponseWriter.WriteHeader(status)
+}
+
+func (rr *responseRecorder) Write(b []byte) (int, error) {
+	if rr.status == 0 {
+		rr.status = http.StatusOK
+	}
+	rr.body.Write(b)
+	return rr.ResponseWriter.Write(b)
+}
+
+// CacheResponse returns middleware that caches responses in d.Cache for ttl.
+//
+// Responses are keyed by method, path, query string, the locale detected by
+// Localize and the request headers listed in ResponseCacheOptions.Vary, so
+// /pt-BR/about and /about are cached apart even though both are routed as
+// /about. Hits are served straight from the cache
+// with an Age header and "X-Cache: HIT". Requests from authenticated sessions,
+// responses that set cookies and responses marked "Cache-Control: private" or
+// "no-store" are never cached. The middleware must run after SessionLoad, which
+// the default router already installs. If d.Cache is nil the middleware is a no-op.
+//
+// Example:
+//
+//	mux.With(d.CacheResponse(5*time.Minute, devify.ResponseCacheOptions{
+//	    Vary: []string{"Accept-Language"},
+//	})).Get("/", handlers.Home)
+func (d *Devify) CacheResponse(ttl time.Duration, opts ...ResponseCacheOptions) func(http.Handler) http.Handler {
+	var o ResponseCacheOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	if len(o.Methods) == 0 {
+		o.Methods = []string{http.MethodGet, http.MethodHead}
+	}
+	if len(o.StatusCodes) == 0 {
+		o.StatusCodes = []int{http.StatusOK}
+	}
+
+	seconds := int(ttl.Seconds())
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if d.Cache == nil || !slices.Contains(o.Methods, r.Method) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if !o.CacheAuthenticated && d.Session != nil && d.Session.Exists(r.Context(), "userID") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			for _, h := range o.Vary {
+				w.Header().Add("Vary", h)
+			}
+
+			key := responseCacheKey(r, o.Vary)
+			if d.serveCachedResponse(w, key) {
+				return
+			}
+
+			w.Header().Set("X-Cache", "MISS")
+			rec := &responseRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r)
+
+			if !cacheableResponse(rec, o.StatusCodes) {
+				return
+			}
+
+			header := w.Header().Clone()
+			header.Del("Set-Cookie")
+			header.Del("X-Cache")
+			entry := cachedResponse{
+				Status:   rec.status,
+				Header:   header,
+				Body:     rec.body.Bytes(),
+				StoredAt: time.Now(),
+			}
+			if err := d.Cache.Set(key, entry, seconds); err != nil {
+				d.logResponseCacheError("store", key, err)
+			}
+		})
+	}
+}
+
+// serveCachedResponse writes the response stored under key, if any, and reports whether it did.
+func (d *Devify) serveCachedResponse(w http.ResponseWriter, key string) bool {
+	value, err := d.Cache.Get(key)
+	if err != nil {
+		d.logResponseCacheError("read", key, err)
+		return false
+	}
+	entry, ok := value.(cachedResponse)
+	if !ok {
+		return false
+	}
+
+	for k, values := range entry.Header {
+		w.Header()[k] = values
+	}
+	w.Header().Set("Age", strconv.Itoa(int(time.Since(entry.StoredAt).Seconds())))
+	w.Header().Set("X-Cache", "HIT")
+	w.WriteHeader(entry.Status)
+	_, _ = w.Write(entry.Body)
+	return true
+}
+
+// PurgeResponseCache removes cached responses whose path matches routePattern.
+//
+// The pattern may use chi-style parameters, which match any value, or Redis glob
+// wildcards. All methods, query strings and Vary variants of a path are removed.
+//
+// Example:
+//
+//	err := d.PurgeResponseCache("/users/{id}") // Purges /users/1, /users/2, ...
+//	err = d.PurgeResponseCache("/blog/*")      // Purges everything under /blog/
+func (d *Devify) PurgeResponseCache(routePattern string) error {
+	if d.Cache == nil {
+		return nil
+	}
+	pattern := routeParam.ReplaceAllString(routePattern, "*")
+	return d.Cache.EmptyByMatch(responseCachePrefix + ":" + pattern)
+}
+
+// FlushResponseCache removes every response stored by CacheResponse.
+func (d *Devify) FlushResponseCache() error {
+	if d.Cache == nil {
+		return nil
+	}
+	return d.Cache.EmptyByMatch(responseCachePrefix)
+}
+
+// responseCacheKey builds the cache key for r as "response:<path>:<method>:<hash>",
+// where hash covers the sorted query string, the locale of the request and the
+// values of the vary headers.
+// Keeping the path in clear text lets PurgeResponseCache match it by pattern.
+func responseCacheKey(r *http.Request, vary []string) string {
+	h := sha256.New()
+	h.Write([]byte(r.URL.Query().Encode()))
+	h.Write([]byte{0})
+	h.Write([]byte(i18n.LocaleFrom(r.Context())))
+
+	headers := append([]string(nil), vary...)
+	sort.Strings(headers)
+	for _, name := range headers {
+		h.Write([]byte{0})
+		h.Write([]byte(http.CanonicalHeaderKey(name) + "=" + r.Header.Get(name)))
+	}
+
+	return strings.Join([]string{
+		responseCachePrefix,
+		r.URL.Path,
+		r.Method,
+		hex.EncodeToString(h.Sum(nil))[:32],
+	}, ":")
+}
+
+// cacheableResponse reports whether a recorded response may be stored.
+func cacheableResponse(rec *responseRecorder, statusCodes []int) bool {
+	if !slices.Contains(statusCodes, rec.status) {
+		return false
+	}
+	if rec.Header().Get("Set-Cookie") != "" {
+		return false
+	}
+	cc := strings.ToLower(rec.Header().Get("Cache-Control"))
+	return !strings.Contains(cc, "private") && !strings.Contains(cc, "no-store")
+}
+
+func (d *Devify) logResponseCacheError(action, key string, err error) {
+	if d.ErrorLog != nil {
+		d.ErrorLog.Printf("response cache: failed to %s %s: %v", action, key, err)
+	}
+}
@@ -0,0 +1,185 @@
+package devify
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jorgeSader/devify/i18n"
+)
+
+// countingHandler counts its calls and answers with the call number, so a
+// cached response can be told apart from a fresh one.
+func countingHandler(calls *int, header func(w http.ResponseWriter, r *http.Request)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		if header != nil {
+			header(w, r)
+		}
+		fmt.Fprintf(w, "call %d", *calls)
+	}
+}
+
+func TestCacheResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		first    string // URL of the first request
+		second   string // URL of the second request
+		header   func(w http.ResponseWriter, r *http.Request)
+		wantHit  bool
+		wantBody string // body of the second response
+	}{
+		{"repeated GET", http.MethodGet, "/page", "/page", nil, true, "call 1"},
+		{"different query", http.MethodGet, "/page?p=1", "/page?p=2", nil, false, "call 2"},
+		{"reordered query", http.MethodGet, "/page?a=1&b=2", "/page?b=2&a=1", nil, true, "call 1"},
+		{"POST", http.MethodPost, "/page", "/page", nil, false, "call 2"},
+		{"Set-Cookie", http.MethodGet, "/page", "/page", func(w http.ResponseWriter, r *http.Request) {
+			http.SetCookie(w, &http.Cookie{Name: "seen", Value: "1"})
+		}, false, "call 2"},
+		{"Cache-Control private", http.MethodGet, "/page", "/page", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Cache-Control", "private")
+		}, false, "call 2"},
+		{"not found", http.MethodGet, "/page", "/page", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}, false, "call 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newTestApp(t)
+			d.CSRFExempt("/page")
+			calls := 0
+			d.Routes.With(d.CacheResponse(time.Minute)).MethodFunc(tt.method, "/page", countingHandler(&calls, tt.header))
+
+			serve(d, httptest.NewRequest(tt.method, tt.first, nil))
+			w := serve(d, httptest.NewRequest(tt.method, tt.second, nil))
+
+			if hit := w.Header().Get("X-Cache") == "HIT"; hit != tt.wantHit {
+				t.Errorf("X-Cache = %q; want hit %v", w.Header().Get("X-Cache"), tt.wantHit)
+			}
+			if w.Body.String() != tt.wantBody {
+				t.Errorf("body = %q; want %q", w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestCacheResponse_Vary(t *testing.T) {
+	d := newTestApp(t)
+	calls := 0
+	d.Routes.With(d.CacheResponse(time.Minute, ResponseCacheOptions{Vary: []string{"Accept-Language"}})).
+		Get("/page", countingHandler(&calls, nil))
+
+	for _, tt := range []struct {
+		lang, body string
+	}{
+		{"en", "call 1"},
+		{"pt", "call 2"},
+		{"en", "call 1"},
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/page", nil)
+		r.Header.Set("Accept-Language", tt.lang)
+		if w := serve(d, r); w.Body.String() != tt.body {
+			t.Errorf("Accept-Language %s: body = %q; want %q", tt.lang, w.Body.String(), tt.body)
+		}
+	}
+}
+
+func TestCacheResponse_Locale(t *testing.T) {
+	d := newTestApp(t)
+	d.I18n = i18n.New("en")
+	for _, locale := range []string{"en", "pt-BR"} {
+		if err := d.I18n.AddMessages(locale, map[string]interface{}{"hello": "hello"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	d.config.locale.urlPrefix = true
+	calls := 0
+	d.Routes.With(d.CacheResponse(time.Minute)).Get("/x", countingHandler(&calls, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s ", d.Locale(r))
+	}))
+
+	for _, tt := range []struct {
+		path, lang, body string
+	}{
+		{"/pt-BR/x", "", "pt-BR call 1"},
+		{"/x", "", "en call 2"},
+		{"/pt-BR/x", "", "pt-BR call 1"},
+		{"/x", "pt-BR", "pt-BR call 1"},
+		{"/x", "en", "en call 2"},
+	} {
+		r := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		if tt.lang != "" {
+			r.Header.Set("Accept-Language", tt.lang)
+		}
+		if w := serve(d, r); w.Body.String() != tt.body {
+			t.Errorf("%s (Accept-Language %q): body = %q; want %q", tt.path, tt.lang, w.Body.String(), tt.body)
+		}
+	}
+}
+
+func TestCacheResponse_Authenticated(t *testing.T) {
+	d := newTestApp(t)
+	calls := 0
+	d.Routes.Get("/login", func(w http.ResponseWriter, r *http.Request) {
+		d.Session.Put(r.Context(), "userID", 1)
+	})
+	d.Routes.With(d.CacheResponse(time.Minute)).Get("/page", countingHandler(&calls, nil))
+
+	cookie := sessionCookie(t, serve(d, httptest.NewRequest(http.MethodGet, "/login", nil)))
+	for want := 1; want <= 2; want++ {
+		r := httptest.NewRequest(http.MethodGet, "/page", nil)
+		r.AddCookie(cookie)
+		w := serve(d, r)
+		if got := fmt.Sprintf("call %d", want); w.Body.String() != got {
+			t.Errorf("request %d: body = %q; want %q", want, w.Body.String(), got)
+		}
+		if w.Header().Get("X-Cache") != "" {
+			t.Errorf("request %d: X-Cache = %q; want none", want, w.Header().Get("X-Cache"))
+		}
+	}
+}
+
+func TestPurgeResponseCache(t *testing.T) {
+	d := newTestApp(t)
+	calls := map[string]*int{"/users/1": new(int), "/users/2": new(int), "/blog/a": new(int)}
+	for path, n := range calls {
+		d.Routes.With(d.CacheResponse(time.Minute)).Get(path, countingHandler(n, nil))
+	}
+
+	tests := []struct {
+		name   string
+		purge  func() error
+		cached map[string]bool // whether each path is still cached afterwards
+	}{
+		{"route pattern", func() error { return d.PurgeResponseCache("/users/{id}") },
+			map[string]bool{"/users/1": false, "/users/2": false, "/blog/a": true}},
+		{"glob", func() error { return d.PurgeResponseCache("/blog/*") },
+			map[string]bool{"/users/1": true, "/users/2": true, "/blog/a": false}},
+		{"flush", d.FlushResponseCache,
+			map[string]bool{"/users/1": false, "/users/2": false, "/blog/a": false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := d.FlushResponseCache(); err != nil {
+				t.Fatal(err)
+			}
+			for path := range calls {
+				serve(d, httptest.NewRequest(http.MethodGet, path, nil))
+			}
+
+			if err := tt.purge(); err != nil {
+				t.Fatal(err)
+			}
+			for path, cached := range tt.cached {
+				w := serve(d, httptest.NewRequest(http.MethodGet, path, nil))
+				if hit := w.Header().Get("X-Cache") == "HIT"; hit != cached {
+					t.Errorf("%s: cached = %v; want %v", path, hit, cached)
+				}
+			}
+		})
+	}
+}
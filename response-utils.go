@@ -14,7 +14,7 @@ import (
 )
 
 // ReadJSON reads a single JSON object from the request body into the provided data interface.
-// It enforces a maximum body size of 1MB and ensures the body contains exactly one JSON value,
+// It enforces the MAX_BODY_SIZE limit (1MB by default) and ensures the body contains exactly one JSON value,
 // rejecting requests with trailing data or multiple JSON objects.
 //
 // The data parameter must be a pointer to a struct where the JSON will be decoded.
@@ -34,8 +34,7 @@ import (
 //	}
 //	err = d.ReadJSON(w, r, &u, false) // Lenient mode: ignores unknown fields
 func (d *Devify) ReadJSON(w http.ResponseWriter, r *http.Request, data interface{}, strict bool) error {
-	const maxBytes = 1048576 // 1MB limit
-	r.Body = http.MaxBytesReader(w, r.Body, int64(maxBytes))
+	r.Body = http.MaxBytesReader(w, r.Body, d.maxBodySize())
 
 	dec := json.NewDecoder(r.Body)
 	if strict {
@@ -184,35 +183,65 @@ func (d *Devify) DownloadFile(w http.ResponseWriter, r *http.Request, pathToFile
 	return nil
 }
 
+// The helpers below predate ErrorStatus and have no request, so they always
+// answer with HTML and render the error page without the locale, flash
+// messages or authentication of the request.
+
+// Error404 responds with the 404 Not Found error page.
+//
+// Deprecated: use ErrorStatus(w, r, http.StatusNotFound), which negotiates
+// JSON and renders the page with the request's data.
 func (d *Devify) Error404(w http.ResponseWriter) {
-	d.ErrorSatus(w, http.StatusNotFound)
+	d.ErrorStatus(w, nil, http.StatusNotFound)
 }
 
+// Error500 responds with the 500 Internal Server Error page.
+//
+// Deprecated: use ServerError(w, r, err), which also logs err, or
+// ErrorStatus(w, r, http.StatusInternalServerError).
 func (d *Devify) Error500(w http.ResponseWriter) {
-	d.ErrorSatus(w, http.StatusInternalServerError)
+	d.ErrorStatus(w, nil, http.StatusInternalServerError)
 }
 
+// ErrorUnauthorized responds with the 401 Unauthorized error page.
+//
+// Deprecated: use ErrorStatus(w, r, http.StatusUnauthorized).
 func (d *Devify) ErrorUnauthorized(w http.ResponseWriter) {
-	d.ErrorSatus(w, http.StatusUnauthorized)
+	d.ErrorStatus(w, nil, http.StatusUnauthorized)
 }
 
+// ErrorForbidden responds with the 403 Forbidden error page.
+//
+// Deprecated: use ErrorStatus(w, r, http.StatusForbidden).
 func (d *Devify) ErrorForbidden(w http.ResponseWriter) {
-	d.ErrorSatus(w, http.StatusForbidden)
+	d.ErrorStatus(w, nil, http.StatusForbidden)
 }
 
+// ErrorBadRequest responds with the 400 Bad Request error page.
+//
+// Deprecated: use ErrorStatus(w, r, http.StatusBadRequest).
 func (d *Devify) ErrorBadRequest(w http.ResponseWriter) {
-	d.ErrorSatus(w, http.StatusBadRequest)
+	d.ErrorStatus(w, nil, http.StatusBadRequest)
 }
 
+// ErrorTooManyRequests responds with the 429 Too Many Requests error page.
+//
+// Deprecated: use ErrorStatus(w, r, http.StatusTooManyRequests).
 func (d *Devify) ErrorTooManyRequests(w http.ResponseWriter) {
-	d.ErrorSatus(w, http.StatusTooManyRequests)
+	d.ErrorStatus(w, nil, http.StatusTooManyRequests)
 }
 
+// ErrorPaymentRequired responds with the 402 Payment Required error page.
+//
+// Deprecated: use ErrorStatus(w, r, http.StatusPaymentRequired).
 func (d *Devify) ErrorPaymentRequired(w http.ResponseWriter) {
-	d.ErrorSatus(w, http.StatusPaymentRequired)
+	d.ErrorStatus(w, nil, http.StatusPaymentRequired)
 }
 
+// ErrorSatus responds with the error page for status.
+//
+// Deprecated: use ErrorStatus(w, r, status), which negotiates JSON and
+// renders the page with the request's data.
 func (d *Devify) ErrorSatus(w http.ResponseWriter, status int) {
-	http.Error(w, http.StatusText(status), status)
-	w.WriteHeader(status)
+	d.ErrorStatus(w, nil, status)
 }
@@ -14,8 +14,12 @@ func (d *Devify) routes() http.Handler {
 	if d.Debug {
 		mux.Use(middleware.Logger)
 	}
-	mux.Use(middleware.Recoverer)
+	mux.Use(d.Recoverer)
 	mux.Use(d.SessionLoad)
+	mux.Use(d.Localize)
+	if envBool("CSRF_PROTECTION", true) {
+		mux.Use(d.CSRF)
+	}
 
 	return mux
 }
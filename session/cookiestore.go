@@ -0,0 +1,258 @@
+package session
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/alexedwards/scs/v2"
+)
+
+// MaxCookieSize is the largest cookie, name and value included, that browsers are
+// guaranteed to store.
+const MaxCookieSize = 4096
+
+var (
+	// ErrCookieTooLarge is returned when the sealed session does not fit in a cookie.
+	ErrCookieTooLarge = errors.New("session data too large for cookie store")
+	// ErrNoEncryptionKey is returned when a CookieStore has no key to seal sessions with.
+	ErrNoEncryptionKey = errors.New("cookie session store requires an encryption key")
+	// ErrCookieStoreMiddleware is returned when a CookieStore is used without its LoadAndSave middleware.
+	ErrCookieStoreMiddleware = errors.New("cookie session store must be used with CookieStore.LoadAndSave")
+)
+
+type cookieContextKey struct{}
+
+// sealedCookie carries the sealed session value from CommitCtx back to LoadAndSave.
+type sealedCookie struct {
+	value string
+}
+
+// CookieStore is a stateless scs session store that keeps the session data in
+// the session cookie itself, sealed with AES-256-GCM so it can be neither read
+// nor modified by the client.
+//
+// Keys are derived from the application's encryption keys. The first key seals
+// new cookies; every key is tried when opening one, so a key can be rotated by
+// prepending the new key and keeping the old one until existing sessions expire.
+//
+// Because scs only hands the store a token, a CookieStore must be paired with its
+// own LoadAndSave middleware instead of scs.SessionManager.LoadAndSave.
+type CookieStore struct {
+	CookieName string // name of the session cookie, used to enforce MaxSize
+	MaxSize    int    // cookie size limit in bytes; defaults to MaxCookieSize
+
+	aeads []cipher.AEAD
+}
+
+// NewCookieStore creates a CookieStore sealing with the first of keys and
+// accepting cookies sealed with any of them. Empty keys are ignored.
+func NewCookieStore(keys ...string) *CookieStore {
+	cs := &CookieStore{MaxSize: MaxCookieSize}
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		sum := sha256.Sum256([]byte(key))
+		block, err := aes.NewCipher(sum[:])
+		if err != nil {
+			continue // unreachable: a SHA-256 sum is always a valid AES-256 key
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			continue
+		}
+		cs.aeads = append(cs.aeads, aead)
+	}
+	return cs
+}
+
+// LoadAndSave is the CookieStore counterpart of scs.SessionManager.LoadAndSave.
+// It loads the session from the sealed cookie and, when the session changes,
+// writes the newly sealed data back to the cookie.
+func (cs *CookieStore) LoadAndSave(sm *scs.SessionManager, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Cookie")
+
+		var token string
+		cookie, err := r.Cookie(sm.Cookie.Name)
+		if err == nil {
+			token = cookie.Value
+		}
+
+		sealed := &sealedCookie{value: token}
+		ctx, err := sm.Load(context.WithValue(r.Context(), cookieContextKey{}, sealed), token)
+		if err != nil {
+			sm.ErrorFunc(w, r, err)
+			return
+		}
+
+		sr := r.WithContext(ctx)
+		sw := &cookieResponseWriter{
+			ResponseWriter: w,
+			request:        sr,
+			sessionManager: sm,
+			sealed:         sealed,
+		}
+
+		next.ServeHTTP(sw, sr)
+
+		if !sw.written {
+			sw.commit()
+		}
+	})
+}
+
+// cookieResponseWriter commits the session before the first byte of the response is written.
+type cookieResponseWriter struct {
+	http.ResponseWriter
+	request        *http.Request
+	sessionManager *scs.SessionManager
+	sealed         *sealedCookie
+	written        bool
+}
+
+func (sw *cookieResponseWriter) commit() {
+	sw.written = true
+	sm := sw.sessionManager
+	ctx := sw.request.Context()
+
+	switch sm.Status(ctx) {
+	case scs.Modified:
+		_, expiry, err := sm.Commit(ctx)
+		if err != nil {
+			sm.ErrorFunc(sw.ResponseWriter, sw.request, err)
+			return
+		}
+		sm.WriteSessionCookie(ctx, sw.ResponseWriter, sw.sealed.value, expiry)
+	case scs.Destroyed:
+		sm.WriteSessionCookie(ctx, sw.ResponseWriter, "", time.Time{})
+	}
+}
+
+func (sw *cookieResponseWriter) Write(b []byte) (int, error) {
+	if !sw.written {
+		sw.commit()
+	}
+	return sw.ResponseWriter.Write(b)
+}
+
+func (sw *cookieResponseWriter) WriteHeader(code int) {
+	if !sw.written {
+		sw.commit()
+	}
+	sw.ResponseWriter.WriteHeader(code)
+}
+
+func (sw *cookieResponseWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
+
+// FindCtx opens the sealed token. Tampered, expired or undecryptable tokens are
+// reported as not found, as required by scs.Store.
+func (cs *CookieStore) FindCtx(_ context.Context, token string) ([]byte, bool, error) {
+	b, expiry, ok := cs.open(token)
+	if !ok || time.Now().After(expiry) {
+		return nil, false, nil
+	}
+	return b, true, nil
+}
+
+// CommitCtx seals b and hands the result to LoadAndSave to be written as the cookie value.
+func (cs *CookieStore) CommitCtx(ctx context.Context, _ string, b []byte, expiry time.Time) error {
+	sealed, ok := ctx.Value(cookieContextKey{}).(*sealedCookie)
+	if !ok {
+		return ErrCookieStoreMiddleware
+	}
+
+	value, err := cs.seal(b, expiry)
+	if err != nil {
+		return err
+	}
+
+	maxSize := cs.MaxSize
+	if maxSize <= 0 {
+		maxSize = MaxCookieSize
+	}
+	if size := len(cs.CookieName) + 1 + len(value); size > maxSize {
+		return fmt.Errorf("%w: sealed session is %d bytes, limit is %d; store large values server-side", ErrCookieTooLarge, size, maxSize)
+	}
+
+	sealed.value = value
+	return nil
+}
+
+// DeleteCtx clears the sealed value so the cookie is not re-sent.
+func (cs *CookieStore) DeleteCtx(ctx context.Context, _ string) error {
+	if sealed, ok := ctx.Value(cookieContextKey{}).(*sealedCookie); ok {
+		sealed.value = ""
+	}
+	return nil
+}
+
+// Find implements scs.Store.
+func (cs *CookieStore) Find(token string) ([]byte, bool, error) {
+	return cs.FindCtx(context.Background(), token)
+}
+
+// Commit implements scs.Store. It always fails because the sealed value can only
+// be returned through the request context set up by LoadAndSave.
+func (cs *CookieStore) Commit(token string, b []byte, expiry time.Time) error {
+	return cs.CommitCtx(context.Background(), token, b, expiry)
+}
+
+// Delete implements scs.Store.
+func (cs *CookieStore) Delete(token string) error {
+	return cs.DeleteCtx(context.Background(), token)
+}
+
+// seal encrypts the expiry and data with the current key and returns them
+// base64url encoded as nonce || ciphertext.
+func (cs *CookieStore) seal(b []byte, expiry time.Time) (string, error) {
+	if len(cs.aeads) == 0 {
+		return "", ErrNoEncryptionKey
+	}
+	aead := cs.aeads[0]
+
+	plain := make([]byte, 8+len(b))
+	binary.BigEndian.PutUint64(plain, uint64(expiry.Unix()))
+	copy(plain[8:], b)
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plain)+aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(aead.Seal(nonce, nonce, plain, nil)), nil
+}
+
+// open decrypts a value produced by seal, trying each key in turn.
+func (cs *CookieStore) open(value string) ([]byte, time.Time, bool) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+
+	for _, aead := range cs.aeads {
+		if len(data) < aead.NonceSize() {
+			continue
+		}
+		nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+		plain, err := aead.Open(nil, nonce, ciphertext, nil)
+		if err != nil || len(plain) < 8 {
+			continue
+		}
+		expiry := time.Unix(int64(binary.BigEndian.Uint64(plain)), 0)
+		return plain[8:], expiry, true
+	}
+	return nil, time.Time{}, false
+}
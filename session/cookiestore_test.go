@@ -0,0 +1,156 @@
+package session
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alexedwards/scs/v2"
+)
+
+// newCookieSessionManager returns a session manager using a CookieStore sealed with keys.
+func newCookieSessionManager(keys ...string) *scs.SessionManager {
+	sm := scs.New()
+	sm.Cookie.Name = "devify"
+	cs := NewCookieStore(keys...)
+	cs.CookieName = sm.Cookie.Name
+	sm.Store = cs
+	return sm
+}
+
+// doCookieRequest runs handler behind the cookie store middleware, sending cookie if not nil.
+func doCookieRequest(sm *scs.SessionManager, cookie *http.Cookie, handler http.HandlerFunc) *httptest.ResponseRecorder {
+	cs := sm.Store.(*CookieStore)
+	r := httptest.NewRequest("GET", "/", nil)
+	if cookie != nil {
+		r.AddCookie(cookie)
+	}
+	w := httptest.NewRecorder()
+	cs.LoadAndSave(sm, handler).ServeHTTP(w, r)
+	return w
+}
+
+func sessionCookie(t *testing.T, w *httptest.ResponseRecorder) *http.Cookie {
+	t.Helper()
+	for _, c := range w.Result().Cookies() {
+		if c.Name == "devify" {
+			return c
+		}
+	}
+	t.Fatal("no session cookie written")
+	return nil
+}
+
+func TestCookieStore_RoundTrip(t *testing.T) {
+	sm := newCookieSessionManager("current-key")
+
+	w := doCookieRequest(sm, nil, func(w http.ResponseWriter, r *http.Request) {
+		sm.Put(r.Context(), "userID", 42)
+	})
+	cookie := sessionCookie(t, w)
+
+	var got int
+	doCookieRequest(sm, cookie, func(w http.ResponseWriter, r *http.Request) {
+		got = sm.GetInt(r.Context(), "userID")
+	})
+	if got != 42 {
+		t.Errorf("userID = %d, want 42", got)
+	}
+}
+
+func TestCookieStore_KeyRotation(t *testing.T) {
+	oldSM := newCookieSessionManager("old-key")
+	w := doCookieRequest(oldSM, nil, func(w http.ResponseWriter, r *http.Request) {
+		oldSM.Put(r.Context(), "userID", 7)
+	})
+	cookie := sessionCookie(t, w)
+
+	tests := []struct {
+		name string
+		keys []string
+		want int
+	}{
+		{"rotated key accepted", []string{"new-key", "old-key"}, 7},
+		{"retired key rejected", []string{"new-key"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sm := newCookieSessionManager(tt.keys...)
+			var got int
+			doCookieRequest(sm, cookie, func(w http.ResponseWriter, r *http.Request) {
+				got = sm.GetInt(r.Context(), "userID")
+			})
+			if got != tt.want {
+				t.Errorf("userID = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCookieStore_Tampered(t *testing.T) {
+	cs := NewCookieStore("key")
+	value, err := cs.seal([]byte("data"), time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("seal() error = %v", err)
+	}
+
+	raw, _ := base64.RawURLEncoding.DecodeString(value)
+	raw[len(raw)/2] ^= 0xff
+	tampered := base64.RawURLEncoding.EncodeToString(raw)
+
+	tests := []struct {
+		name      string
+		token     string
+		wantFound bool
+	}{
+		{"valid", value, true},
+		{"tampered", tampered, false},
+		{"garbage", "not-a-session", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, found, err := cs.Find(tt.token)
+			if err != nil {
+				t.Fatalf("Find() error = %v", err)
+			}
+			if found != tt.wantFound {
+				t.Errorf("Find() found = %v, want %v", found, tt.wantFound)
+			}
+		})
+	}
+
+	expired, _ := cs.seal([]byte("data"), time.Now().Add(-time.Minute))
+	if _, found, _ := cs.Find(expired); found {
+		t.Error("Find() found an expired session")
+	}
+}
+
+func TestCookieStore_TooLarge(t *testing.T) {
+	sm := newCookieSessionManager("key")
+
+	var commitErr error
+	sm.ErrorFunc = func(w http.ResponseWriter, r *http.Request, err error) {
+		commitErr = err
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+
+	doCookieRequest(sm, nil, func(w http.ResponseWriter, r *http.Request) {
+		sm.Put(r.Context(), "blob", strings.Repeat("x", MaxCookieSize))
+	})
+
+	if !errors.Is(commitErr, ErrCookieTooLarge) {
+		t.Errorf("commit error = %v, want ErrCookieTooLarge", commitErr)
+	}
+}
+
+func TestCookieStore_NoKey(t *testing.T) {
+	if _, err := NewCookieStore().seal([]byte("data"), time.Now()); !errors.Is(err, ErrNoEncryptionKey) {
+		t.Errorf("seal() error = %v, want ErrNoEncryptionKey", err)
+	}
+}
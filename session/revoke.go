@@ -0,0 +1,93 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/alexedwards/scs/v2"
+)
+
+// UserIDKey is the session key under which the authenticated user's ID is stored.
+const UserIDKey = "userID"
+
+// ErrNotIterable is returned when the session store cannot enumerate its sessions,
+// as is the case for the cookie store.
+var ErrNotIterable = errors.New("session store does not support listing sessions")
+
+// Info describes an active session.
+type Info struct {
+	Token    string    // session token as stored in the session store
+	Deadline time.Time // absolute expiry of the session
+}
+
+// UserSessions returns every active session belonging to userID.
+//
+// It walks all sessions in the store, so it is meant for account pages and
+// administrative actions rather than per-request use. The SQL, Redis and
+// in-memory stores are supported; other stores return ErrNotIterable.
+func UserSessions(ctx context.Context, sm *scs.SessionManager, userID interface{}) ([]Info, error) {
+	if !iterable(sm.Store) {
+		return nil, ErrNotIterable
+	}
+
+	var sessions []Info
+	err := sm.Iterate(ctx, func(ctx context.Context) error {
+		if belongsTo(sm, ctx, userID) {
+			sessions = append(sessions, Info{
+				Token:    sm.Token(ctx),
+				Deadline: sm.Deadline(ctx),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions for user %v: %w", userID, err)
+	}
+	return sessions, nil
+}
+
+// RevokeUserSessions deletes every session belonging to userID except the one
+// whose token is keep (pass "" to revoke all of them), and returns how many
+// sessions were revoked.
+func RevokeUserSessions(ctx context.Context, sm *scs.SessionManager, userID interface{}, keep string) (int, error) {
+	if !iterable(sm.Store) {
+		return 0, ErrNotIterable
+	}
+
+	revoked := 0
+	err := sm.Iterate(ctx, func(ctx context.Context) error {
+		if !belongsTo(sm, ctx, userID) || sm.Token(ctx) == keep {
+			return nil
+		}
+		if err := sm.Destroy(ctx); err != nil {
+			return err
+		}
+		revoked++
+		return nil
+	})
+	if err != nil {
+		return revoked, fmt.Errorf("failed to revoke sessions for user %v: %w", userID, err)
+	}
+	return revoked, nil
+}
+
+// belongsTo reports whether the session in ctx is authenticated as userID.
+// IDs are compared by their string form so that an int stored at login matches
+// an int64 or string passed by the caller.
+func belongsTo(sm *scs.SessionManager, ctx context.Context, userID interface{}) bool {
+	if sm.Deadline(ctx).Before(time.Now()) {
+		return false
+	}
+	value := sm.Get(ctx, UserIDKey)
+	return value != nil && fmt.Sprint(value) == fmt.Sprint(userID)
+}
+
+func iterable(store scs.Store) bool {
+	switch store.(type) {
+	case scs.IterableStore, scs.IterableCtxStore:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alexedwards/scs/v2"
+	"github.com/alexedwards/scs/v2/memstore"
+)
+
+// newStoredSession commits a session with the given values to sm's store and returns its token.
+func newStoredSession(t *testing.T, sm *scs.SessionManager, values map[string]interface{}) string {
+	t.Helper()
+	ctx, err := sm.Load(context.Background(), "")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	for k, v := range values {
+		sm.Put(ctx, k, v)
+	}
+	token, _, err := sm.Commit(ctx)
+	if err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+	return token
+}
+
+func TestRevokeUserSessions(t *testing.T) {
+	sm := scs.New()
+	sm.Store = memstore.NewWithCleanupInterval(time.Hour)
+
+	phone := newStoredSession(t, sm, map[string]interface{}{UserIDKey: 1})
+	laptop := newStoredSession(t, sm, map[string]interface{}{UserIDKey: 1})
+	other := newStoredSession(t, sm, map[string]interface{}{UserIDKey: 2})
+	newStoredSession(t, sm, map[string]interface{}{"guest": true})
+
+	sessions, err := UserSessions(context.Background(), sm, int64(1))
+	if err != nil {
+		t.Fatalf("UserSessions() error = %v", err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("UserSessions() returned %d sessions, want 2", len(sessions))
+	}
+
+	revoked, err := RevokeUserSessions(context.Background(), sm, "1", laptop)
+	if err != nil {
+		t.Fatalf("RevokeUserSessions() error = %v", err)
+	}
+	if revoked != 1 {
+		t.Errorf("RevokeUserSessions() revoked %d sessions, want 1", revoked)
+	}
+
+	tests := []struct {
+		name      string
+		token     string
+		wantFound bool
+	}{
+		{"revoked session", phone, false},
+		{"kept session", laptop, true},
+		{"other user", other, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, found, err := sm.Store.Find(tt.token)
+			if err != nil {
+				t.Fatalf("Find() error = %v", err)
+			}
+			if found != tt.wantFound {
+				t.Errorf("Find() found = %v, want %v", found, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestRevokeUserSessions_NotIterable(t *testing.T) {
+	sm := scs.New()
+	sm.Store = NewCookieStore("key")
+
+	if _, err := RevokeUserSessions(context.Background(), sm, 1, ""); !errors.Is(err, ErrNotIterable) {
+		t.Errorf("RevokeUserSessions() error = %v, want ErrNotIterable", err)
+	}
+}
@@ -7,21 +7,33 @@ import (
 	"strings"
 	"time"
 
+	"github.com/alexedwards/scs/mongodbstore"
 	"github.com/alexedwards/scs/mysqlstore"
 	"github.com/alexedwards/scs/postgresstore"
-	//"github.com/alexedwards/scs/redisstore"
+	"github.com/alexedwards/scs/redisstore"
 	"github.com/alexedwards/scs/sqlite3store"
 	"github.com/alexedwards/scs/v2"
+	"github.com/gomodule/redigo/redis"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type Session struct {
-	CookieLifetime string
-	CookiePersist  string
-	CookieSecure   string
-	CookieName     string
-	CookieDomain   string
-	SessionType    string
-	BDPool         *sql.DB
+	CookieLifetime  string
+	CookiePersist   string
+	CookieSecure    string
+	CookieHttpOnly  string
+	CookieSameSite  string
+	CookieName      string
+	CookieDomain    string
+	IdleTimeout     string
+	SessionType     string
+	TableName       string // table (or MongoDB collection) holding sessions; defaults to "sessions"
+	CleanupInterval string // minutes between removals of expired sessions; defaults to 5, "0" disables
+	BDPool          *sql.DB
+	MongoDatabase   *mongo.Database
+	RedisPool       *redis.Pool
+	RedisPrefix     string
+	EncryptionKeys  []string // keys for the cookie store; the first seals, all are accepted
 }
 
 func (d *Session) InitSession() *scs.SessionManager {
@@ -43,31 +55,83 @@ func (d *Session) InitSession() *scs.SessionManager {
 		secure = true
 	}
 
+	// should cookies be hidden from javascript? (defaults to true)
+	httpOnly := strings.ToLower(d.CookieHttpOnly) != "false"
+
+	// which SameSite mode? (defaults to lax)
+	sameSite := http.SameSiteLaxMode
+	switch strings.ToLower(d.CookieSameSite) {
+	case "strict":
+		sameSite = http.SameSiteStrictMode
+	case "none":
+		sameSite = http.SameSiteNoneMode
+		secure = true // browsers reject SameSite=None cookies that are not secure
+	}
+
+	// how long may a session sit idle before it expires? (defaults to no idle timeout)
+	idleMinutes, err := strconv.Atoi(d.IdleTimeout)
+	if err != nil || idleMinutes < 0 {
+		idleMinutes = 0
+	}
+
+	// how often should expired sessions be removed? (defaults to 5min)
+	cleanupMinutes, err := strconv.Atoi(d.CleanupInterval)
+	if err != nil || cleanupMinutes < 0 {
+		cleanupMinutes = 5
+	}
+	cleanupInterval := time.Duration(cleanupMinutes) * time.Minute
+
 	// create session
 	session := scs.New()
 	session.Lifetime = time.Duration(minutes) * time.Minute
+	session.IdleTimeout = time.Duration(idleMinutes) * time.Minute
 	session.Cookie.Persist = persist
 	session.Cookie.Name = d.CookieName
 	session.Cookie.Secure = secure
+	session.Cookie.HttpOnly = httpOnly
 	session.Cookie.Domain = d.CookieDomain
-	session.Cookie.SameSite = http.SameSiteLaxMode
+	session.Cookie.SameSite = sameSite
 
 	// which session store?
 	switch strings.ToLower(d.SessionType) {
 	case "redis":
-		//session.Store = redisstore.New(d.BDPool)
+		if d.RedisPrefix != "" {
+			session.Store = redisstore.NewWithPrefix(d.RedisPool, d.RedisPrefix)
+		} else {
+			session.Store = redisstore.New(d.RedisPool)
+		}
 
 	case "mysql", "mariadb":
-		session.Store = mysqlstore.New(d.BDPool)
+		session.Store = mysqlstore.NewWithConfig(d.BDPool, mysqlstore.Config{
+			TableName:       d.TableName,
+			CleanUpInterval: cleanupInterval,
+		})
 
 	case "postgres", "postgresql":
-		session.Store = postgresstore.New(d.BDPool)
+		session.Store = postgresstore.NewWithConfig(d.BDPool, postgresstore.Config{
+			TableName:       d.TableName,
+			CleanUpInterval: cleanupInterval,
+		})
 
 	case "sqlite", "sqlite3", "libsql", "turso", "tursodb":
-		session.Store = sqlite3store.New(d.BDPool)
+		session.Store = sqlite3store.NewWithConfig(d.BDPool, sqlite3store.Config{
+			TableName:       d.TableName,
+			CleanUpInterval: cleanupInterval,
+		})
+
+	case "mongo", "mongodb":
+		session.Store = mongodbstore.NewWithConfig(d.MongoDatabase, mongodbstore.Config{
+			CollectionName:  d.TableName,
+			CleanupInterval: cleanupInterval,
+		})
+
+	case "cookie":
+		cookieStore := NewCookieStore(d.EncryptionKeys...)
+		cookieStore.CookieName = session.Cookie.Name
+		session.Store = cookieStore
 
 	default:
-		// cookie
+		// in-memory
 	}
 
 	return session
@@ -2,10 +2,15 @@ package session
 
 import (
 	"fmt"
+	"net/http"
 	"reflect"
 	"testing"
+	"time"
 
+	"github.com/alexedwards/scs/redisstore"
 	"github.com/alexedwards/scs/v2"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gomodule/redigo/redis"
 )
 
 func TestSession_InitSession(t *testing.T) {
@@ -48,3 +53,91 @@ func TestSession_InitSession(t *testing.T) {
 	}
 
 }
+
+func TestSession_InitSession_Redis(t *testing.T) {
+	s := miniredis.RunT(t)
+	pool := &redis.Pool{
+		Dial: func() (redis.Conn, error) {
+			return redis.Dial("tcp", s.Addr())
+		},
+	}
+	defer pool.Close()
+
+	d := &Session{
+		CookieLifetime: "100",
+		CookieName:     "devify",
+		SessionType:    "redis",
+		RedisPool:      pool,
+		RedisPrefix:    "test-devify-session:",
+	}
+
+	sm := d.InitSession()
+
+	if _, ok := sm.Store.(*redisstore.RedisStore); !ok {
+		t.Fatalf("wrong store returned for redis session; got %T", sm.Store)
+	}
+
+	expiry := time.Now().Add(time.Minute)
+	if err := sm.Store.Commit("token", []byte("data"), expiry); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	if !s.Exists("test-devify-session:token") {
+		t.Errorf("expected key test-devify-session:token, keys = %v", s.Keys())
+	}
+
+	b, found, err := sm.Store.Find("token")
+	if err != nil || !found || string(b) != "data" {
+		t.Errorf("Find() = %q, %v, %v; want \"data\", true, nil", b, found, err)
+	}
+}
+
+func TestSession_InitSession_Hardening(t *testing.T) {
+	tests := []struct {
+		name         string
+		sameSite     string
+		secure       string
+		httpOnly     string
+		idleTimeout  string
+		wantSameSite http.SameSite
+		wantSecure   bool
+		wantHttpOnly bool
+		wantIdle     time.Duration
+	}{
+		{"defaults", "", "", "", "", http.SameSiteLaxMode, false, true, 0},
+		{"lax", "lax", "true", "true", "30", http.SameSiteLaxMode, true, true, 30 * time.Minute},
+		{"strict", "Strict", "false", "", "15", http.SameSiteStrictMode, false, true, 15 * time.Minute},
+		{"none forces secure", "none", "false", "false", "15", http.SameSiteNoneMode, true, false, 15 * time.Minute},
+		{"unknown SameSite", "sideways", "", "", "", http.SameSiteLaxMode, false, true, 0},
+		{"invalid idle timeout", "", "", "", "soon", http.SameSiteLaxMode, false, true, 0},
+		{"negative idle timeout", "", "", "", "-5", http.SameSiteLaxMode, false, true, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &Session{
+				CookieName:     "devify",
+				CookieSameSite: tt.sameSite,
+				CookieSecure:   tt.secure,
+				CookieHttpOnly: tt.httpOnly,
+				IdleTimeout:    tt.idleTimeout,
+				SessionType:    "cookie",
+			}
+
+			sm := d.InitSession()
+
+			if sm.Cookie.SameSite != tt.wantSameSite {
+				t.Errorf("SameSite = %v, want %v", sm.Cookie.SameSite, tt.wantSameSite)
+			}
+			if sm.Cookie.Secure != tt.wantSecure {
+				t.Errorf("Secure = %v, want %v", sm.Cookie.Secure, tt.wantSecure)
+			}
+			if sm.Cookie.HttpOnly != tt.wantHttpOnly {
+				t.Errorf("HttpOnly = %v, want %v", sm.Cookie.HttpOnly, tt.wantHttpOnly)
+			}
+			if sm.IdleTimeout != tt.wantIdle {
+				t.Errorf("IdleTimeout = %v, want %v", sm.IdleTimeout, tt.wantIdle)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package devify
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/alexedwards/scs/v2"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-chi/chi/v5"
+	"github.com/gomodule/redigo/redis"
+	"github.com/jorgeSader/devify/cache"
+	"github.com/jorgeSader/devify/render"
+)
+
+var testRedisServer *miniredis.Miniredis
+
+func TestMain(m *testing.M) {
+	s, err := miniredis.Run()
+	if err != nil {
+		panic(err)
+	}
+	testRedisServer = s
+
+	code := m.Run()
+	s.Close()
+	os.Exit(code)
+}
+
+// newTestApp returns a Devify with an in-memory session store, a Redis cache
+// on miniredis and the default router, the way New sets them up.
+func newTestApp(t *testing.T) *Devify {
+	t.Helper()
+	testRedisServer.FlushAll()
+
+	pool := &redis.Pool{
+		Dial: func() (redis.Conn, error) {
+			return redis.Dial("tcp", testRedisServer.Addr())
+		},
+	}
+	t.Cleanup(func() { _ = pool.Close() })
+
+	d := &Devify{
+		AppName:  "test",
+		InfoLog:  log.New(io.Discard, "", 0),
+		ErrorLog: log.New(io.Discard, "", 0),
+		RootPath: t.TempDir(),
+		Session:  scs.New(),
+		Cache:    &cache.RedisCache{Conn: pool, Prefix: "test-devify"},
+	}
+	d.Routes = d.routes().(*chi.Mux)
+	return d
+}
+
+// useViews gives d a Go template renderer reading the views in files, keyed by
+// their path below views/.
+func useViews(d *Devify, files map[string]string) {
+	fsys := fstest.MapFS{}
+	for name, content := range files {
+		fsys["views/"+name] = &fstest.MapFile{Data: []byte(content)}
+	}
+	d.Render = &render.Render{Renderer: "go", FS: fsys, Session: d.Session}
+}
+
+// withSession returns r with a new session loaded, for calling handlers
+// without the router.
+func withSession(t *testing.T, d *Devify, r *http.Request) *http.Request {
+	t.Helper()
+	ctx, err := d.Session.Load(r.Context(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r.WithContext(ctx)
+}
+
+// serve sends r through the routes of d.
+func serve(d *Devify, r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	d.Routes.ServeHTTP(w, r)
+	return w
+}
+
+// sessionCookie returns the session cookie set by w, to send it with the next request.
+func sessionCookie(t *testing.T, w *httptest.ResponseRecorder) *http.Cookie {
+	t.Helper()
+	for _, c := range w.Result().Cookies() {
+		if c.Name == "session" {
+			return c
+		}
+	}
+	t.Fatal("no session cookie was set")
+	return nil
+}
@@ -0,0 +1,74 @@
+package devify
+
+import (
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/jorgeSader/devify/render"
+)
+
+// immutableCacheControl lets browsers and proxies keep fingerprinted assets for a year.
+const immutableCacheControl = "public, max-age=31536000, immutable"
+
+// embeddedFS returns FS unless the application runs in debug mode, where files
+// are read from RootPath so edits show up without rebuilding the binary.
+func (d *Devify) embeddedFS() fs.FS {
+	if d.Debug {
+		return nil
+	}
+	return d.FS
+}
+
+// PublicFS returns the public/ directory, from FS in production and from disk
+// under RootPath in debug mode or when FS is not set.
+func (d *Devify) PublicFS() fs.FS {
+	if fsys := d.embeddedFS(); fsys != nil {
+		sub, err := fs.Sub(fsys, "public")
+		if err == nil {
+			return sub
+		}
+	}
+	return os.DirFS(d.RootPath + "/public")
+}
+
+// Static returns a handler serving the files in PublicFS. Fingerprinted files
+// listed in the asset manifest (see "devify assets build") are served with a
+// far-future Cache-Control header, since their names change with their content.
+// Directory listings are not served.
+//
+// Example:
+//
+//	//go:embed views public
+//	var files embed.FS
+//
+//	app := &devify.Devify{FS: files}
+//	err := app.New(rootPath)
+//	app.Routes.Handle("/public/*", http.StripPrefix("/public", app.Static()))
+func (d *Devify) Static() http.Handler {
+	fsys := d.PublicFS()
+	fileServer := http.FileServerFS(fsys)
+
+	manifest, err := render.ReadManifest(fsys)
+	if err != nil && d.ErrorLog != nil {
+		d.ErrorLog.Printf("Failed to read asset manifest: %v", err)
+	}
+	fingerprinted := manifest.Fingerprinted()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+		if name == "" {
+			name = "."
+		}
+		if info, err := fs.Stat(fsys, name); err != nil || info.IsDir() {
+			http.NotFound(w, r)
+			return
+		}
+		if _, ok := fingerprinted[name]; ok {
+			w.Header().Set("Cache-Control", immutableCacheControl)
+		}
+		fileServer.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,100 @@
+package devify
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jorgeSader/devify/filesystem"
+)
+
+// openStorage creates Devify.Storage from the STORAGE environment variable:
+// "local" (the default), "s3" or "sftp". The local driver stores files in
+// STORAGE_ROOT (storage/ by default) and serves temporary URLs, signed with
+// ENCRYPTION_KEY, below STORAGE_URL ("/storage" by default). A Storage set
+// before New is kept.
+func (d *Devify) openStorage() error {
+	if d.Storage != nil {
+		return nil
+	}
+
+	switch driver := strings.ToLower(os.Getenv("STORAGE")); driver {
+	case "", "local":
+		root := os.Getenv("STORAGE_ROOT")
+		if root == "" {
+			root = filepath.Join(d.RootPath, "storage")
+		}
+		baseURL := os.Getenv("STORAGE_URL")
+		if baseURL == "" {
+			baseURL = "/storage"
+		}
+
+		local, err := filesystem.NewLocal(root, baseURL, []byte(d.EncryptionKey))
+		if err != nil {
+			return err
+		}
+		d.Storage = local
+
+		// Serve temporary URLs here unless STORAGE_URL points at another host.
+		if strings.HasPrefix(local.BaseURL, "/") {
+			d.Routes.Handle(local.BaseURL+"/*", http.StripPrefix(local.BaseURL, local.Handler()))
+		}
+
+	case "s3":
+		s3, err := filesystem.NewS3(filesystem.S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_KEY"),
+			SecretKey: os.Getenv("S3_SECRET"),
+			UseSSL:    envBool("S3_USE_SSL", true),
+			PathStyle: envBool("S3_PATH_STYLE", false),
+		})
+		if err != nil {
+			return err
+		}
+		d.Storage = s3
+
+	case "sftp":
+		cfg := filesystem.SFTPConfig{
+			Host:                  os.Getenv("SFTP_HOST"),
+			Port:                  envInt("SFTP_PORT", 22),
+			User:                  os.Getenv("SFTP_USER"),
+			Password:              os.Getenv("SFTP_PASSWORD"),
+			KnownHostsFile:        os.Getenv("SFTP_KNOWN_HOSTS"),
+			InsecureIgnoreHostKey: envBool("SFTP_INSECURE", false),
+			Root:                  os.Getenv("SFTP_ROOT"),
+			Timeout:               envDuration("SFTP_TIMEOUT", 10*time.Second),
+		}
+		if keyFile := os.Getenv("SFTP_KEY_FILE"); keyFile != "" {
+			key, err := os.ReadFile(keyFile)
+			if err != nil {
+				return err
+			}
+			cfg.PrivateKey = key
+		}
+
+		sftp, err := filesystem.NewSFTP(cfg)
+		if err != nil {
+			return err
+		}
+		d.Storage = sftp
+
+	default:
+		return fmt.Errorf("unsupported STORAGE %q: use local, s3 or sftp", driver)
+	}
+	return nil
+}
+
+// closeStorage closes drivers holding a connection, such as SFTP.
+func (d *Devify) closeStorage() {
+	if closer, ok := d.Storage.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			d.ErrorLog.Printf("Failed to close storage: %v", err)
+		}
+	}
+}
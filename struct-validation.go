@@ -0,0 +1,437 @@
+package devify
+
+import (
+	"encoding"
+	"fmt"
+	"math"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// structRule applies one validate tag rule to the field at path, whose value
+// is also in v.Data as a string. param is the text after "=", if any.
+type structRule func(v *Validation, path string, value reflect.Value, param string)
+
+// structRules maps validate tag rules to the Validation methods implementing them.
+var structRules = map[string]structRule{
+	"email":    func(v *Validation, path string, _ reflect.Value, _ string) { v.IsEmail(path) },
+	"phone":    func(v *Validation, path string, _ reflect.Value, _ string) { v.IsPhone(path) },
+	"url":      func(v *Validation, path string, _ reflect.Value, _ string) { v.IsURL(path) },
+	"int":      func(v *Validation, path string, _ reflect.Value, _ string) { v.IsInt(path) },
+	"float":    func(v *Validation, path string, _ reflect.Value, _ string) { v.IsFloat(path) },
+	"numeric":  func(v *Validation, path string, _ reflect.Value, _ string) { v.IsFloat(path) },
+	"boolean":  func(v *Validation, path string, _ reflect.Value, _ string) { v.IsBoolean(path) },
+	"nospaces": func(v *Validation, path string, _ reflect.Value, _ string) { v.HasNoSpaces(path) },
+	"date":     func(v *Validation, path string, _ reflect.Value, layout string) { v.IsDate(path, "", layout) },
+	"contains": func(v *Validation, path string, _ reflect.Value, sub string) { v.Contains(path, sub) },
+	"matches":  func(v *Validation, path string, _ reflect.Value, pattern string) { v.Matches(path, pattern) },
+	"oneof":    func(v *Validation, path string, _ reflect.Value, param string) { v.OneOf(path, strings.Fields(param)) },
+	"between": func(v *Validation, path string, _ reflect.Value, param string) {
+		bounds := strings.Fields(param)
+		if len(bounds) != 2 {
+			v.setErr(fmt.Errorf("devify: validate rule between on %s needs two bounds, got %q", path, param))
+			return
+		}
+		lo, loOK := v.ruleFloat(path, "between", bounds[0])
+		hi, hiOK := v.ruleFloat(path, "between", bounds[1])
+		if loOK && hiOK {
+			v.Between(path, lo, hi)
+		}
+	},
+	"min": func(v *Validation, path string, value reflect.Value, param string) { v.size(path, value, param, true) },
+	"max": func(v *Validation, path string, value reflect.Value, param string) { v.size(path, value, param, false) },
+
+	// Cross-field rules name other fields of the same struct by their JSON name.
+	"same": func(v *Validation, path string, _ reflect.Value, other string) { v.Same(path, sibling(path, other)) },
+	"different": func(v *Validation, path string, _ reflect.Value, other string) {
+		v.Different(path, sibling(path, other))
+	},
+	"confirmed": func(v *Validation, path string, _ reflect.Value, _ string) { v.Confirmed(path) },
+	"after": func(v *Validation, path string, _ reflect.Value, date string) {
+		v.After(path, siblingOr(v, path, date))
+	},
+	"before": func(v *Validation, path string, _ reflect.Value, date string) {
+		v.Before(path, siblingOr(v, path, date))
+	},
+
+	// Database rules take "table column"; unique also takes the ID of the row
+	// to ignore, or the field holding it, and the column of that ID.
+	"unique": func(v *Validation, path string, _ reflect.Value, param string) {
+		params, ok := v.tableColumn("unique", param, 2)
+		if !ok {
+			return
+		}
+		var ignoreID interface{}
+		if len(params) > 2 {
+			ignoreID = params[2]
+			if other := sibling(path, params[2]); v.Data.Has(other) {
+				ignoreID = v.Data.Get(other)
+			}
+		}
+		if len(params) > 3 {
+			v.unique(path, params[0], params[1], ignoreID, params[3], nil)
+			return
+		}
+		v.Unique(path, params[0], params[1], ignoreID)
+	},
+	"exists": func(v *Validation, path string, _ reflect.Value, param string) {
+		params, ok := v.tableColumn("exists", param, 0)
+		if !ok {
+			return
+		}
+		v.Exists(path, params[0], params[1])
+	},
+}
+
+// siblingOr returns the path of the field name next to path if there is one,
+// and name itself otherwise.
+func siblingOr(v *Validation, path, name string) string {
+	if other := sibling(path, name); v.Data.Has(other) {
+		return other
+	}
+	return name
+}
+
+// ValidateStruct validates the struct dst points to with the rules in its
+// validate tags, using the default locale for messages. It returns nil when
+// dst is valid and the failed *Validation otherwise, which WriteError turns
+// into a 422 response. Use d.Validator(r).Struct(dst) for messages in the
+// request's locale.
+//
+// Example:
+//
+//	type Signup struct {
+//	    Email string   `json:"email" validate:"required,email,max=255"`
+//	    Name  string   `json:"name" validate:"required,min=3"`
+//	    Plan  string   `json:"plan" validate:"oneof=free pro"`
+//	    Tags  []string `json:"tags" validate:"max=5"`
+//	}
+//	if err := d.ValidateStruct(&payload); err != nil {
+//	    d.WriteError(w, r, err)
+//	    return
+//	}
+func (d *Devify) ValidateStruct(dst interface{}) error {
+	v := &Validation{Data: url.Values{}, Errors: make(map[string]string), translator: d.I18n, db: d.DB}
+	if v.Struct(dst).Valid() {
+		return nil
+	}
+	return v
+}
+
+// Struct validates the struct dst points to with the rules in its validate
+// tags, a comma-separated list of:
+//
+//	required             not the zero value (use a pointer to allow false or 0)
+//	email, phone, url    IsEmail, IsPhone, IsURL
+//	int, float, numeric  IsInt, IsFloat
+//	boolean, nospaces    IsBoolean, HasNoSpaces
+//	date, date=layout    IsDate, optionally with a single layout
+//	min=n, max=n         length of strings, count of slices and maps, or value of numbers
+//	between=a b          Between
+//	oneof=a b c          In
+//	contains=s           Contains
+//	matches=regexp       Matches (the pattern can't contain commas)
+//	same=f, different=f  Same, Different
+//	confirmed            Confirmed, against the <name>_confirmation field
+//	after=d, before=d    After, Before; d is a field or a date
+//	required_if=f value  RequiredIf
+//	required_with=f g    RequiredWith
+//	unique=table column  Unique; "table column id" ignores the row whose id
+//	                     column has id, the value of the field named id or
+//	                     else id itself, and "table column id idcolumn"
+//	                     compares it with idcolumn instead
+//	exists=table column  Exists
+//
+// Rules registered with RegisterRule can be used too. Cross-field rules name
+// other fields of the same struct by their JSON name.
+//
+// Rules other than the required ones are skipped for empty strings, slices and maps
+// and nil pointers; omitempty is accepted for readability. Nested structs,
+// pointers to structs and slices of structs are validated too.
+//
+// Errors are keyed by the JSON names of the fields, joined with dots, with
+// slice indexes as segments, e.g. "address.city" or "items.0.sku". The
+// values checked are also stored in v.Data under the same keys.
+//
+// A dst that isn't a struct, an unknown rule or a malformed parameter are
+// programming errors: they are reported by Err, make v invalid and become a
+// 500 response in WriteError.
+func (v *Validation) Struct(dst interface{}) *Validation {
+	if v.Data == nil {
+		v.Data = url.Values{}
+	}
+	if v.Errors == nil {
+		v.Errors = make(map[string]string)
+	}
+
+	rv := reflect.ValueOf(dst)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		v.setErr(fmt.Errorf("devify: Struct needs a non-nil pointer to a struct or a struct, got %T", dst))
+		return v
+	}
+
+	v.validateStruct(rv, "")
+	return v
+}
+
+// validateStruct checks the fields of rv, naming them below prefix.
+func (v *Validation) validateStruct(rv reflect.Value, prefix string) {
+	v.setStructData(rv, prefix)
+
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, tagged := jsonName(field)
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && !tagged {
+			v.validateNested(rv.Field(i), prefix)
+			continue
+		}
+
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+		if tag := field.Tag.Get("validate"); tag != "" && tag != "-" {
+			v.checkField(path, rv.Field(i), tag)
+		}
+		v.validateNested(rv.Field(i), path)
+	}
+}
+
+// setStructData stores the scalar fields of rv in v.Data, so cross-field
+// rules can read the fields next to the one they check.
+func (v *Validation) setStructData(rv reflect.Value, prefix string) {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, tagged := jsonName(field)
+		if !field.IsExported() || name == "-" || (field.Anonymous && !tagged) {
+			continue
+		}
+
+		value := reflect.Indirect(rv.Field(i))
+		switch value.Kind() {
+		case reflect.Struct:
+			if value.Type() != timeType {
+				continue
+			}
+		case reflect.Slice, reflect.Array, reflect.Map, reflect.Interface:
+			continue
+		}
+
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+		v.Data.Set(path, fieldString(rv.Field(i)))
+	}
+}
+
+// validateNested validates the structs in value, if it is or holds any.
+func (v *Validation) validateNested(value reflect.Value, path string) {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return
+		}
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		if value.Type() != timeType {
+			v.validateStruct(value, path)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			elemPath := strconv.Itoa(i)
+			if path != "" {
+				elemPath = path + "." + elemPath
+			}
+			v.validateNested(value.Index(i), elemPath)
+		}
+	}
+}
+
+// checkField applies the rules of a validate tag to value.
+func (v *Validation) checkField(path string, value reflect.Value, tag string) {
+	empty := isEmptyValue(value)
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch name {
+		case "", "omitempty":
+			continue
+		case "required", "required_if", "required_with":
+			if isMissing(value) && v.isRequired(path, name, param) {
+				v.AddError(path, v.message("required", "This field is required."))
+				return
+			}
+			continue
+		}
+		if empty {
+			continue
+		}
+
+		apply, ok := lookupRule(name)
+		if !ok {
+			v.setErr(fmt.Errorf("devify: unknown validate rule %q on %s", name, path))
+			continue
+		}
+		v.Data.Set(path, fieldString(value))
+		apply(v, path, value, param)
+	}
+}
+
+// isRequired reports whether the required rule name applies to the field at
+// path, given the values of the fields next to it.
+func (v *Validation) isRequired(path, name, param string) bool {
+	switch name {
+	case "required_if":
+		other, value, _ := strings.Cut(strings.TrimSpace(param), " ")
+		return strings.TrimSpace(v.Data.Get(sibling(path, other))) == strings.TrimSpace(value)
+	case "required_with":
+		for _, other := range strings.Fields(param) {
+			if v.Has(sibling(path, other)) {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+// size checks the min (or max) rule for value.
+func (v *Validation) size(path string, value reflect.Value, param string, min bool) {
+	rule := "max"
+	if min {
+		rule = "min"
+	}
+	n, ok := v.ruleFloat(path, rule, param)
+	if !ok {
+		return
+	}
+	value = reflect.Indirect(value)
+
+	switch value.Kind() {
+	case reflect.String:
+		if min {
+			v.MinLength(path, int(n))
+		} else {
+			v.MaxLength(path, int(n))
+		}
+	case reflect.Slice, reflect.Array, reflect.Map:
+		count := float64(value.Len())
+		if min && count < n {
+			v.AddError(path, v.message("min_items", "Must have at least {min} items.", "min", param))
+		} else if !min && count > n {
+			v.AddError(path, v.message("max_items", "Must not have more than {max} items.", "max", param))
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		f, _ := strconv.ParseFloat(fieldString(value), 64)
+		if min && f < n {
+			v.AddError(path, v.message("min", "Must be at least {min}.", "min", param))
+		} else if !min && f > n {
+			v.AddError(path, v.message("max", "Must not be greater than {max}.", "max", param))
+		}
+	default:
+		v.setErr(fmt.Errorf("devify: validate rule %s can't be used on %s (%s)", rule, path, value.Type()))
+	}
+}
+
+// ruleFloat parses the numeric parameter of rule on the field at path,
+// recording an error in v when it isn't a number.
+func (v *Validation) ruleFloat(path, rule, param string) (float64, bool) {
+	f, err := strconv.ParseFloat(strings.TrimSpace(param), 64)
+	if err != nil || math.IsNaN(f) {
+		v.setErr(fmt.Errorf("devify: validate rule %s on %s needs a number, got %q", rule, path, param))
+		return 0, false
+	}
+	return f, true
+}
+
+// jsonName returns the JSON name of field and whether its json tag sets one.
+func jsonName(field reflect.StructField) (string, bool) {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" {
+		return field.Name, false
+	}
+	return name, true
+}
+
+// isMissing reports whether value fails the required rule.
+func isMissing(value reflect.Value) bool {
+	if !value.IsValid() {
+		return true
+	}
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return value.IsNil()
+	case reflect.String:
+		return strings.TrimSpace(value.String()) == ""
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return value.Len() == 0
+	default:
+		return value.IsZero()
+	}
+}
+
+// isEmptyValue reports whether the rules other than required skip value.
+// Numbers and bools are never empty, so min=1 still rejects 0.
+func isEmptyValue(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.String, reflect.Slice, reflect.Map:
+		return isMissing(value)
+	default:
+		return !value.IsValid()
+	}
+}
+
+// fieldString formats value the way it would appear in a form.
+func fieldString(value reflect.Value) string {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return ""
+		}
+		value = value.Elem()
+	}
+
+	if value.Type() == timeType {
+		return value.Interface().(time.Time).Format("2006-01-02 15:04")
+	}
+	if m, ok := value.Interface().(encoding.TextMarshaler); ok {
+		text, err := m.MarshalText()
+		if err == nil {
+			return string(text)
+		}
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		return value.String()
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(value.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'f', -1, 64)
+	default:
+		return fmt.Sprint(value.Interface())
+	}
+}
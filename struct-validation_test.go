@@ -0,0 +1,172 @@
+package devify
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testAddress struct {
+	City string `json:"city" validate:"required"`
+	Zip  string `json:"zip" validate:"matches=^[0-9]{5}$"`
+}
+
+type testItem struct {
+	SKU      string `json:"sku" validate:"required,nospaces"`
+	Quantity int    `json:"quantity" validate:"min=1,max=10"`
+}
+
+// AuditFields is exported because, like encoding/json, validation only
+// descends into exported embedded structs.
+type AuditFields struct {
+	Note string `json:"note" validate:"max=5"`
+}
+
+type testSignup struct {
+	AuditFields
+	Email                string       `json:"email" validate:"required,email,max=30"`
+	Name                 string       `json:"name,omitempty" validate:"omitempty,min=3"`
+	Plan                 string       `json:"plan" validate:"oneof=free pro"`
+	Age                  int          `json:"age" validate:"between=18 130"`
+	Website              *string      `json:"website" validate:"url"`
+	Tags                 []string     `json:"tags" validate:"max=2"`
+	Password             string       `json:"password" validate:"required,min=8,confirmed"`
+	PasswordConfirmation string       `json:"password_confirmation"`
+	Username             string       `json:"username" validate:"different=email"`
+	Company              string       `json:"company" validate:"required_if=plan pro"`
+	VATNumber            string       `json:"vat_number" validate:"required_with=company"`
+	StartsAt             time.Time    `json:"starts_at"`
+	EndsAt               time.Time    `json:"ends_at" validate:"after=starts_at"`
+	Address              *testAddress `json:"address"`
+	Items                []testItem   `json:"items"`
+	Secret               string       `json:"-" validate:"required"`
+	unexported           string       `validate:"required"`
+}
+
+// validSignup returns a testSignup that passes every rule.
+func validSignup() testSignup {
+	return testSignup{
+		Email:                "ada@example.com",
+		Plan:                 "free",
+		Age:                  36,
+		Password:             "correct horse",
+		PasswordConfirmation: "correct horse",
+		StartsAt:             time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		EndsAt:               time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC),
+		Address:              &testAddress{City: "London", Zip: "12345"},
+		Items:                []testItem{{SKU: "A-1", Quantity: 1}},
+	}
+}
+
+func TestValidateStruct(t *testing.T) {
+	badURL := "not a url"
+
+	tests := []struct {
+		name   string
+		modify func(s *testSignup)
+		errors []string // fields with errors
+	}{
+		{"valid", func(s *testSignup) {}, nil},
+		{"required", func(s *testSignup) { s.Email, s.Password = "  ", "" }, []string{"email", "password"}},
+		{"email and max", func(s *testSignup) { s.Email = strings.Repeat("a", 31) }, []string{"email"}},
+		{"omitempty skips empty", func(s *testSignup) { s.Name = "" }, nil},
+		{"min on a string", func(s *testSignup) { s.Name = "Al" }, []string{"name"}},
+		{"oneof", func(s *testSignup) { s.Plan = "gold" }, []string{"plan"}},
+		{"between", func(s *testSignup) { s.Age = 12 }, []string{"age"}},
+		{"zero number is checked", func(s *testSignup) { s.Age = 0 }, []string{"age"}},
+		{"pointer", func(s *testSignup) { s.Website = &badURL }, []string{"website"}},
+		{"max on a slice", func(s *testSignup) { s.Tags = []string{"a", "b", "c"} }, []string{"tags"}},
+		{"confirmed", func(s *testSignup) { s.PasswordConfirmation = "other" }, []string{"password"}},
+		{"different", func(s *testSignup) { s.Username = s.Email }, []string{"username"}},
+		{"required_if", func(s *testSignup) { s.Plan = "pro" }, []string{"company"}},
+		{"required_with", func(s *testSignup) { s.Company = "ACME" }, []string{"vat_number"}},
+		{"after a field", func(s *testSignup) { s.EndsAt = s.StartsAt.Add(-time.Hour) }, []string{"ends_at"}},
+		{"embedded struct", func(s *testSignup) { s.Note = "too long" }, []string{"note"}},
+		{"nested struct", func(s *testSignup) { s.Address.City, s.Address.Zip = "", "ABC" }, []string{"address.city", "address.zip"}},
+		{"nil nested struct", func(s *testSignup) { s.Address = nil }, nil},
+		{"slice of structs", func(s *testSignup) {
+			s.Items = append(s.Items, testItem{SKU: "B 2", Quantity: 11})
+		}, []string{"items.1.quantity", "items.1.sku"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newTestApp(t)
+			s := validSignup()
+			tt.modify(&s)
+
+			err := d.ValidateStruct(&s)
+			if tt.errors == nil {
+				if err != nil {
+					t.Fatalf("ValidateStruct = %v; want nil", err)
+				}
+				return
+			}
+
+			var v *Validation
+			if !errors.As(err, &v) {
+				t.Fatalf("ValidateStruct = %v; want *Validation", err)
+			}
+			if v.Err() != nil {
+				t.Fatalf("Err = %v", v.Err())
+			}
+			var fields []string
+			for field := range v.Errors {
+				fields = append(fields, field)
+			}
+			sort.Strings(fields)
+			if !reflect.DeepEqual(fields, tt.errors) {
+				t.Errorf("errors = %v; want fields %v", v.Errors, tt.errors)
+			}
+		})
+	}
+}
+
+func TestValidation_StructInvalidUse(t *testing.T) {
+	tests := []struct {
+		name string
+		dst  interface{}
+		want string // part of the error
+	}{
+		{"nil", nil, "needs a non-nil pointer"},
+		{"nil pointer", (*testSignup)(nil), "needs a non-nil pointer"},
+		{"not a struct", new(string), "needs a non-nil pointer"},
+		{"unknown rule", &struct {
+			Name string `validate:"required,shiny"`
+		}{Name: "x"}, `unknown validate rule "shiny"`},
+		{"between with one bound", &struct {
+			Age int `validate:"between=1"`
+		}{Age: 3}, "needs two bounds"},
+		{"min without a number", &struct {
+			Name string `validate:"min=abc"`
+		}{Name: "x"}, "needs a number"},
+		{"max on a bool", &struct {
+			OK bool `validate:"max=1"`
+		}{}, "can't be used"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newTestApp(t)
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			v := d.Validator(r).Struct(tt.dst)
+
+			if v.Valid() {
+				t.Error("Valid = true; want false")
+			}
+			if v.Err() == nil || !strings.Contains(v.Err().Error(), tt.want) {
+				t.Errorf("Err = %v; want it to contain %q", v.Err(), tt.want)
+			}
+
+			err := d.ValidateStruct(tt.dst)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ValidateStruct = %v; want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
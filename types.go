@@ -1,6 +1,9 @@
 package devify
 
-import "database/sql"
+import (
+	"database/sql"
+	"time"
+)
 
 // initPaths defines the root path and folder structure for initializing the application.
 type initPaths struct {
@@ -9,11 +12,32 @@ type initPaths struct {
 }
 
 type cookieConfig struct {
-	name     string
-	lifeTime string
-	persist  string
-	secure   string
-	domain   string
+	name        string
+	lifeTime    string
+	persist     string
+	secure      string
+	httpOnly    string
+	sameSite    string
+	domain      string
+	idleTimeout string
+}
+
+// sessionConfig holds the storage settings for the SQL and MongoDB session stores.
+type sessionConfig struct {
+	table           string
+	cleanupInterval string
+}
+
+// localeConfig holds the i18n settings.
+type localeConfig struct {
+	defaultLocale string
+	urlPrefix     bool // detect the locale from the first path segment, e.g. /pt-BR/about
+}
+
+// mongoConfig holds the MongoDB connection settings.
+type mongoConfig struct {
+	uri      string
+	database string
 }
 
 type databaseConfig struct {
@@ -26,8 +50,24 @@ type Database struct {
 	Pool     *sql.DB
 }
 
+// redisConfig holds the connection, TLS, pool and Sentinel settings for Redis.
 type redisConfig struct {
-	host     string
-	password string
-	prefix   string
+	host             string
+	username         string
+	password         string
+	prefix           string
+	database         int
+	cluster          bool
+	useTLS           bool
+	tlsCAFile        string
+	tlsSkipVerify    bool
+	dialTimeout      time.Duration
+	readTimeout      time.Duration
+	writeTimeout     time.Duration
+	maxIdle          int
+	maxActive        int
+	idleTimeout      time.Duration
+	sentinelAddrs    []string
+	sentinelMaster   string
+	sentinelPassword string
 }
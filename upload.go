@@ -0,0 +1,296 @@
+package devify
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// defaultMaxUploadSize is the multipart request limit when MAX_UPLOAD_SIZE is not set.
+const defaultMaxUploadSize = 32 << 20 // 32MB
+
+// UploadStore saves uploaded files. path is slash-separated and relative to
+// the root of the store.
+type UploadStore interface {
+	Put(ctx context.Context, path string, r io.Reader) error
+}
+
+// UploadOptions restricts the files UploadFile accepts. The zero value accepts
+// any number of files of any type up to MAX_UPLOAD_SIZE, except active content.
+//
+// Active content is HTML, XML, SVG and XHTML: a browser opening such a file
+// from the application's origin, as it would from public/uploads, runs the
+// scripts in it. UploadFile refuses these types unless AllowedTypes lists
+// them exactly; "text/*" or "image/*" do not allow them.
+type UploadOptions struct {
+	MaxSize      int64       // maximum size of each file in bytes
+	AllowedTypes []string    // MIME types detected from content, e.g. "image/png" or "image/*"; see above for active content
+	MaxFiles     int         // maximum number of files in the field; 0 means no limit
+	Store        UploadStore // where files are saved; Devify.Uploads when nil
+}
+
+// UploadedFile describes a file saved by UploadFile.
+type UploadedFile struct {
+	Field        string // form field the file was sent in
+	OriginalName string // file name sent by the client; never use it as a path
+	Name         string // random name the file was saved under
+	Path         string // dest and Name, relative to the root of the store
+	Size         int64
+	ContentType  string // MIME type detected from the content
+}
+
+// UploadFile saves the files sent in the multipart form field of r under the
+// dest directory of the store, each with a random name keeping the extension
+// of its detected MIME type. Every file is checked against opts, which may be
+// nil, before any is saved.
+//
+// The store defaults to Devify.Uploads, which saves to public/uploads on
+// disk. When the application serves public/ from an embedded FS, serve the
+// uploads directory separately.
+//
+// Errors caused by the request are *Error values, ready for WriteError: 413
+// when the request exceeds MAX_UPLOAD_SIZE and 422 with Fields when there is
+// no file or a file is too large, of a type not allowed or active content
+// (see UploadOptions).
+//
+// Example:
+//
+//	files, err := d.UploadFile(r, "avatar", "avatars", &devify.UploadOptions{
+//	    MaxSize:      2 << 20,
+//	    AllowedTypes: []string{"image/png", "image/jpeg"},
+//	    MaxFiles:     1,
+//	})
+//	if err != nil {
+//	    d.WriteError(w, r, err)
+//	    return
+//	}
+//	user.Avatar = files[0].Path
+func (d *Devify) UploadFile(r *http.Request, field, dest string, opts *UploadOptions) ([]UploadedFile, error) {
+	if opts == nil {
+		opts = &UploadOptions{}
+	}
+	store := opts.Store
+	if store == nil {
+		store = d.uploadStore()
+	}
+
+	// Cleaning dest as an absolute path keeps it inside the store.
+	dir := strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(dest)), "/")
+
+	if err := d.parseMultipart(r); err != nil {
+		return nil, err
+	}
+
+	v := &Validation{Data: r.Form, Errors: make(map[string]string), Req: r, translator: d.I18n}
+	v.IsFile(field)
+	if opts.MaxFiles > 0 && len(v.files(field)) > opts.MaxFiles {
+		v.AddError(field, v.message("max_files", "Must not have more than {max} files.", "max", opts.MaxFiles))
+	}
+	maxSize := opts.MaxSize
+	if maxSize <= 0 {
+		maxSize = d.maxUploadSize()
+	}
+	v.MaxFileSize(field, maxSize)
+	if len(opts.AllowedTypes) > 0 {
+		v.MimeTypes(field, opts.AllowedTypes)
+	}
+	v.refuseActiveContent(field, opts.AllowedTypes)
+	if err := v.Err(); err != nil {
+		return nil, err
+	}
+	if !v.Valid() {
+		return nil, &Error{
+			Status:  http.StatusUnprocessableEntity,
+			Code:    "invalid_upload",
+			Message: v.message("failed", "The given data was invalid."),
+			Fields:  v.Errors,
+		}
+	}
+
+	var uploaded []UploadedFile
+	for _, header := range v.files(field) {
+		file, err := d.saveUpload(r.Context(), store, dir, field, header)
+		if err != nil {
+			return uploaded, err
+		}
+		uploaded = append(uploaded, file)
+	}
+	return uploaded, nil
+}
+
+// saveUpload saves one uploaded file under dir with a random name.
+func (d *Devify) saveUpload(ctx context.Context, store UploadStore, dir, field string, header *multipart.FileHeader) (UploadedFile, error) {
+	contentType, err := detectContentType(header)
+	if err != nil {
+		return UploadedFile{}, err
+	}
+
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return UploadedFile{}, err
+	}
+	name := hex.EncodeToString(random) + uploadExtension(header.Filename, contentType)
+
+	f, err := header.Open()
+	if err != nil {
+		return UploadedFile{}, err
+	}
+	defer f.Close()
+
+	stored := path.Join(dir, name)
+	if err := store.Put(ctx, stored, f); err != nil {
+		return UploadedFile{}, fmt.Errorf("saving upload %s: %w", stored, err)
+	}
+
+	return UploadedFile{
+		Field:        field,
+		OriginalName: header.Filename,
+		Name:         name,
+		Path:         stored,
+		Size:         header.Size,
+		ContentType:  contentType,
+	}, nil
+}
+
+// parseMultipart parses the multipart form of r, limited to MAX_UPLOAD_SIZE.
+func (d *Devify) parseMultipart(r *http.Request) error {
+	if r.MultipartForm != nil {
+		return nil
+	}
+
+	d.limitBody(nil, r)
+	err := r.ParseMultipartForm(d.maxUploadSize())
+
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, http.ErrNotMultipart):
+		return NewError(http.StatusUnsupportedMediaType, "unsupported_media_type",
+			"Files must be sent as multipart/form-data.").Wrap(err)
+	default:
+		return bodyError(err)
+	}
+}
+
+// maxUploadSize returns the configured multipart request limit.
+func (d *Devify) maxUploadSize() int64 {
+	if d.config.maxUploadSize > 0 {
+		return d.config.maxUploadSize
+	}
+	return defaultMaxUploadSize
+}
+
+// uploadStore returns Devify.Uploads, or the public/uploads directory.
+func (d *Devify) uploadStore() UploadStore {
+	if d.Uploads != nil {
+		return d.Uploads
+	}
+	return localUploads(filepath.Join(d.RootPath, "public", "uploads"))
+}
+
+// localUploads is an UploadStore saving files below a directory.
+type localUploads string
+
+func (dir localUploads) Put(_ context.Context, name string, r io.Reader) error {
+	target := filepath.Join(string(dir), filepath.FromSlash(path.Clean("/"+name)))
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		_ = f.Close()
+		_ = os.Remove(target)
+		return err
+	}
+	return f.Close()
+}
+
+// detectContentType returns the MIME type of the content of an uploaded file,
+// without parameters.
+func detectContentType(header *multipart.FileHeader) (string, error) {
+	f, err := header.Open()
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	buf := make([]byte, 512)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(buf[:n]))
+	return contentType, nil
+}
+
+// uploadExtension returns the extension to save a file of contentType with:
+// the client's extension when it fits the type, or one registered for it.
+func uploadExtension(filename, contentType string) string {
+	extensions, _ := mime.ExtensionsByType(contentType)
+	ext := strings.ToLower(filepath.Ext(filename))
+	for _, e := range extensions {
+		if e == ext {
+			return ext
+		}
+	}
+	if len(extensions) > 0 {
+		return extensions[0]
+	}
+	return ""
+}
+
+// activeContentTypes are the types UploadFile refuses unless they are allowed
+// explicitly; see UploadOptions.
+var activeContentTypes = []string{"text/html", "text/xml", "image/svg+xml", "application/xhtml+xml"}
+
+// refuseActiveContent adds an error to field if a file uploaded in it is
+// active content whose type allowed does not list exactly.
+func (v *Validation) refuseActiveContent(field string, allowed []string) {
+	for _, header := range v.files(field) {
+		contentType, err := detectContentType(header)
+		if err != nil {
+			v.setErr(err)
+			return
+		}
+		if !mimeAllowed(contentType, activeContentTypes) {
+			continue
+		}
+		explicit := false
+		for _, a := range allowed {
+			explicit = explicit || strings.EqualFold(strings.TrimSpace(a), contentType)
+		}
+		if !explicit {
+			v.AddError(field, v.message("active_content", "Files of type {type} are not allowed.", "type", contentType))
+			return
+		}
+	}
+}
+
+// mimeAllowed reports whether contentType matches one of allowed, which may
+// use type/* wildcards.
+func mimeAllowed(contentType string, allowed []string) bool {
+	for _, a := range allowed {
+		a = strings.ToLower(strings.TrimSpace(a))
+		if a == contentType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(a, "/*"); ok && strings.HasPrefix(contentType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,221 @@
+package devify
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testFile is a file of a multipart form built by uploadBody.
+type testFile struct {
+	name string
+	data []byte
+}
+
+// uploadBody returns a multipart form with fields and files, sent in field.
+func uploadBody(t *testing.T, fields map[string]string, field string, files ...testFile) (io.Reader, string) {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for name, value := range fields {
+		_ = mw.WriteField(name, value)
+	}
+	for _, f := range files {
+		part, err := mw.CreateFormFile(field, f.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = part.Write(f.data)
+	}
+	if err := mw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &body, mw.FormDataContentType()
+}
+
+// pngImage returns a PNG image of width by height pixels.
+func pngImage(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestUploadFile(t *testing.T) {
+	avatar := pngImage(t, 10, 10)
+	html := []byte("<!DOCTYPE html><script>alert(1)</script>")
+	svg := []byte(`<?xml version="1.0"?><svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script></svg>`)
+	images := &UploadOptions{AllowedTypes: []string{"image/*"}}
+
+	tests := []struct {
+		name   string
+		opts   *UploadOptions
+		files  []testFile
+		status int
+		ext    string // extension of the saved files
+	}{
+		{"image", images, []testFile{{"me.PNG", avatar}}, http.StatusOK, ".png"},
+		{"any type by default", nil, []testFile{{"notes.txt", []byte("hello")}}, http.StatusOK, ".txt"},
+		{"extension from the content", nil, []testFile{{"photo.exe", avatar}}, http.StatusOK, ".png"},
+		{"several files", &UploadOptions{MaxFiles: 2}, []testFile{{"a.png", avatar}, {"b.png", avatar}}, http.StatusOK, ".png"},
+		{"no file", nil, nil, http.StatusUnprocessableEntity, ""},
+		{"type not allowed", images, []testFile{{"me.png", []byte("not an image")}}, http.StatusUnprocessableEntity, ""},
+		{"one file not allowed", images, []testFile{{"a.png", avatar}, {"b.png", []byte("text")}}, http.StatusUnprocessableEntity, ""},
+		{"file too large", &UploadOptions{MaxSize: 64}, []testFile{{"me.png", avatar}}, http.StatusUnprocessableEntity, ""},
+		{"too many files", &UploadOptions{MaxFiles: 1}, []testFile{{"a.png", avatar}, {"b.png", avatar}}, http.StatusUnprocessableEntity, ""},
+
+		{"HTML by default", nil, []testFile{{"page.html", html}}, http.StatusUnprocessableEntity, ""},
+		{"HTML named as an image", images, []testFile{{"me.png", html}}, http.StatusUnprocessableEntity, ""},
+		{"SVG by default", nil, []testFile{{"logo.svg", svg}}, http.StatusUnprocessableEntity, ""},
+		{"HTML with a wildcard", &UploadOptions{AllowedTypes: []string{"text/*"}}, []testFile{{"page.html", html}}, http.StatusUnprocessableEntity, ""},
+		{"HTML allowed explicitly", &UploadOptions{AllowedTypes: []string{"text/html"}}, []testFile{{"page.html", html}}, http.StatusOK, ".html"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newTestApp(t)
+			var saved []UploadedFile
+			d.Routes.Post("/upload", func(w http.ResponseWriter, r *http.Request) {
+				files, err := d.UploadFile(r, "file", "../avatars", tt.opts)
+				if err != nil {
+					d.WriteError(w, r, err)
+					return
+				}
+				saved = files
+			})
+			cookie, token := csrfSession(t, d)
+
+			body, contentType := uploadBody(t, map[string]string{CSRFField: token}, "file", tt.files...)
+			r := httptest.NewRequest(http.MethodPost, "/upload", body)
+			r.AddCookie(cookie)
+			r.Header.Set("Content-Type", contentType)
+			w := serve(d, r)
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d; want %d (%s)", w.Code, tt.status, w.Body.String())
+			}
+			if tt.status != http.StatusOK {
+				if !strings.Contains(w.Body.String(), `"file":`) {
+					t.Errorf("body %s has no error for the file field", w.Body.String())
+				}
+				return
+			}
+
+			if len(saved) != len(tt.files) {
+				t.Fatalf("saved %d files; want %d", len(saved), len(tt.files))
+			}
+			for i, f := range saved {
+				if f.OriginalName != tt.files[i].name || filepath.Ext(f.Name) != tt.ext || f.Path != "avatars/"+f.Name {
+					t.Errorf("saved %+v; want %s as avatars/*%s", f, tt.files[i].name, tt.ext)
+				}
+				data, err := os.ReadFile(filepath.Join(d.RootPath, "public", "uploads", "avatars", f.Name))
+				if err != nil || !bytes.Equal(data, tt.files[i].data) {
+					t.Errorf("stored file = %q, %v", data, err)
+				}
+			}
+		})
+	}
+}
+
+func TestUploadFile_RequestLimits(t *testing.T) {
+	tests := []struct {
+		name        string
+		formToken   bool // send the CSRF token in the form instead of the header
+		size        int
+		contentType string
+		status      int
+	}{
+		{"under MAX_UPLOAD_SIZE", false, 1024, "", http.StatusOK},
+		{"over MAX_UPLOAD_SIZE with a header token", false, 8192, "", http.StatusRequestEntityTooLarge},
+		{"over MAX_UPLOAD_SIZE with a form token", true, 8192, "", http.StatusRequestEntityTooLarge},
+		{"not multipart", false, 10, "application/json", http.StatusUnsupportedMediaType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newTestApp(t)
+			d.config.maxUploadSize = 4096
+			d.Routes.Post("/upload", func(w http.ResponseWriter, r *http.Request) {
+				if _, err := d.UploadFile(r, "file", "", nil); err != nil {
+					d.WriteError(w, r, err)
+				}
+			})
+			cookie, token := csrfSession(t, d)
+
+			fields := map[string]string{}
+			if tt.formToken {
+				fields[CSRFField] = token
+			}
+			body, contentType := uploadBody(t, fields, "file", testFile{"data.txt", bytes.Repeat([]byte("x"), tt.size)})
+			if tt.contentType != "" {
+				contentType = tt.contentType
+			}
+			r := httptest.NewRequest(http.MethodPost, "/upload", body)
+			r.AddCookie(cookie)
+			r.Header.Set("Content-Type", contentType)
+			if !tt.formToken {
+				r.Header.Set(CSRFHeader, token)
+			}
+
+			if w := serve(d, r); w.Code != tt.status {
+				t.Errorf("status = %d; want %d (%s)", w.Code, tt.status, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestValidation_FileRules(t *testing.T) {
+	small := pngImage(t, 10, 20)
+	large := pngImage(t, 300, 200)
+
+	tests := []struct {
+		name  string
+		files []testFile
+		rules func(v *Validation)
+		valid bool
+	}{
+		{"is file", []testFile{{"a.png", small}}, func(v *Validation) { v.IsFile("file") }, true},
+		{"no file", nil, func(v *Validation) { v.IsFile("file") }, false},
+		{"other field", []testFile{{"a.png", small}}, func(v *Validation) { v.IsFile("avatar") }, false},
+		{"max file size", []testFile{{"a.png", small}}, func(v *Validation) { v.MaxFileSize("file", int64(len(small))) }, true},
+		{"file too large", []testFile{{"a.png", large}}, func(v *Validation) { v.MaxFileSize("file", int64(len(small))) }, false},
+		{"mime type", []testFile{{"a.png", small}}, func(v *Validation) { v.MimeTypes("file", []string{"image/png"}) }, true},
+		{"mime wildcard", []testFile{{"a.png", small}}, func(v *Validation) { v.MimeTypes("file", []string{"IMAGE/*"}) }, true},
+		{"mime from content", []testFile{{"a.png", []byte("text")}}, func(v *Validation) { v.MimeTypes("file", []string{"image/png"}) }, false},
+		{"mime of every file", []testFile{{"a.png", small}, {"b.pdf", []byte("%PDF-1.4")}},
+			func(v *Validation) { v.MimeTypes("file", []string{"image/*"}) }, false},
+		{"dimensions", []testFile{{"a.png", small}},
+			func(v *Validation) { v.ImageDimensions("file", Dimensions{MinWidth: 10, MaxHeight: 20}) }, true},
+		{"too small", []testFile{{"a.png", small}},
+			func(v *Validation) { v.ImageDimensions("file", Dimensions{MinWidth: 100}) }, false},
+		{"too large", []testFile{{"a.png", large}},
+			func(v *Validation) { v.ImageDimensions("file", Dimensions{MaxWidth: 200, MaxHeight: 200}) }, false},
+		{"not an image", []testFile{{"a.png", []byte("text")}},
+			func(v *Validation) { v.ImageDimensions("file", Dimensions{}) }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newTestApp(t)
+			body, contentType := uploadBody(t, nil, "file", tt.files...)
+			r := httptest.NewRequest(http.MethodPost, "/", body)
+			r.Header.Set("Content-Type", contentType)
+
+			v := d.Validator(r)
+			tt.rules(v)
+			if v.Valid() != tt.valid {
+				t.Errorf("Valid = %v; want %v (%v, %v)", v.Valid(), tt.valid, v.Errors, v.Err())
+			}
+		})
+	}
+}
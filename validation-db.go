@@ -0,0 +1,225 @@
+package devify
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// identifierPattern matches the table and column names the database rules
+// accept: plain identifiers, optionally qualified with a schema.
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// WithContext sets the context of the database queries made by Unique and
+// Exists. It defaults to the request's context, or context.Background when
+// there is no request.
+func (v *Validation) WithContext(ctx context.Context) *Validation {
+	v.ctx = ctx
+	return v
+}
+
+// SoftDeletes makes Unique and Exists ignore rows whose column (such as
+// deleted_at) is not NULL, i.e., soft-deleted rows.
+func (v *Validation) SoftDeletes(column string) *Validation {
+	v.softDeleteColumn = column
+	return v
+}
+
+// IDColumn sets the column Unique compares its ignoreID with, for tables
+// whose key is not id.
+func (v *Validation) IDColumn(column string) *Validation {
+	v.idColumn = column
+	return v
+}
+
+// Err returns the first error that kept a rule from being checked, such as a
+// failed database query. WriteError answers 500 instead of 422 when it is set.
+func (v *Validation) Err() error {
+	return v.err
+}
+
+// Unique ensures no row of table has field's value in column, as for
+// "email must be unique in users". Pass the ID of the row being updated as
+// ignoreID to exclude it (compared with the id column, or the one set with
+// IDColumn), or nil. Empty values are not checked.
+//
+// Example:
+//
+//	v.SoftDeletes("deleted_at").Unique("email", "users", "email", user.ID)
+//	v.IDColumn("uuid").Unique("slug", "posts", "slug", post.UUID)
+func (v *Validation) Unique(field, table, column string, ignoreID interface{}, message ...string) *Validation {
+	idColumn := v.idColumn
+	if idColumn == "" {
+		idColumn = "id"
+	}
+	return v.unique(field, table, column, ignoreID, idColumn, message)
+}
+
+func (v *Validation) unique(field, table, column string, ignoreID interface{}, idColumn string, message []string) *Validation {
+	value := strings.TrimSpace(v.Data.Get(field))
+	if value == "" {
+		return v
+	}
+
+	defaultMsg := v.message("unique", "Has already been taken.")
+	if len(message) > 0 {
+		defaultMsg = message[0]
+	}
+
+	var where []string
+	var args []interface{}
+	if ignoreID != nil && !reflect.ValueOf(ignoreID).IsZero() {
+		if !identifierPattern.MatchString(idColumn) {
+			v.setErr(fmt.Errorf("checking %s is unique in %s.%s: invalid ID column %q", field, table, column, idColumn))
+			return v
+		}
+		where = append(where, v.quoteIdentifier(idColumn)+" <> ?")
+		args = append(args, ignoreID)
+	}
+
+	found, err := v.rowExists(table, column, value, where, args)
+	if err != nil {
+		v.setErr(fmt.Errorf("checking %s is unique in %s.%s: %w", field, table, column, err))
+		return v
+	}
+	if found {
+		v.AddError(field, defaultMsg)
+	}
+	return v
+}
+
+// Exists ensures a row of table has field's value in column, as for
+// "category_id must exist". Empty values are not checked.
+//
+// Example:
+//
+//	v.Exists("category_id", "categories", "id")
+func (v *Validation) Exists(field, table, column string, message ...string) *Validation {
+	value := strings.TrimSpace(v.Data.Get(field))
+	if value == "" {
+		return v
+	}
+
+	defaultMsg := v.message("exists", "The selected value is invalid.")
+	if len(message) > 0 {
+		defaultMsg = message[0]
+	}
+
+	found, err := v.rowExists(table, column, value, nil, nil)
+	if err != nil {
+		v.setErr(fmt.Errorf("checking %s exists in %s.%s: %w", field, table, column, err))
+		return v
+	}
+	if !found {
+		v.AddError(field, defaultMsg)
+	}
+	return v
+}
+
+// rowExists reports whether table has a row with value in column that also
+// matches the extra conditions, written with ? placeholders.
+func (v *Validation) rowExists(table, column, value string, where []string, args []interface{}) (bool, error) {
+	if v.db.Pool == nil {
+		return false, errors.New("no database connection; set DATABASE_TYPE")
+	}
+	if !identifierPattern.MatchString(table) || !identifierPattern.MatchString(column) {
+		return false, fmt.Errorf("invalid table or column name %q.%q", table, column)
+	}
+
+	conditions := append([]string{v.quoteIdentifier(column) + " = ?"}, where...)
+	if v.softDeleteColumn != "" {
+		if !identifierPattern.MatchString(v.softDeleteColumn) {
+			return false, fmt.Errorf("invalid soft-delete column %q", v.softDeleteColumn)
+		}
+		conditions = append(conditions, v.quoteIdentifier(v.softDeleteColumn)+" IS NULL")
+	}
+
+	query := v.placeholders(fmt.Sprintf("SELECT 1 FROM %s WHERE %s LIMIT 1",
+		v.quoteIdentifier(table), strings.Join(conditions, " AND ")))
+
+	ctx := v.ctx
+	if ctx == nil && v.Req != nil {
+		ctx = v.Req.Context()
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	var one int
+	err := v.db.Pool.QueryRowContext(ctx, query, append([]interface{}{value}, args...)...).Scan(&one)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return false, nil
+	case err != nil:
+		return false, err
+	default:
+		return true, nil
+	}
+}
+
+// isPostgres reports whether the database uses PostgreSQL syntax.
+func (v *Validation) isPostgres() bool {
+	switch strings.ToLower(v.db.DataType) {
+	case "postgres", "postgresql", "pgx":
+		return true
+	}
+	return false
+}
+
+// quoteIdentifier quotes a validated, possibly schema-qualified, identifier
+// for the database dialect.
+func (v *Validation) quoteIdentifier(name string) string {
+	quote := `"`
+	switch strings.ToLower(v.db.DataType) {
+	case "mysql", "mariadb":
+		quote = "`"
+	}
+
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = quote + part + quote
+	}
+	return strings.Join(parts, ".")
+}
+
+// placeholders rewrites the ? placeholders of query as $1, $2, ... for PostgreSQL.
+func (v *Validation) placeholders(query string) string {
+	if !v.isPostgres() {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			fmt.Fprintf(&b, "$%d", n)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// setErr records err unless an earlier one was recorded.
+func (v *Validation) setErr(err error) {
+	if v.err == nil {
+		v.err = err
+	}
+}
+
+// tableColumn parses the "table column" parameter of the unique and exists
+// rules, followed by at most extra more fields. It records an error with
+// setErr and returns false when the parameter has the wrong number of fields.
+func (v *Validation) tableColumn(rule, param string, extra int) ([]string, bool) {
+	parts := strings.Fields(param)
+	if len(parts) < 2 || len(parts) > 2+extra {
+		v.setErr(fmt.Errorf("devify: validate rule %s needs a table and a column, got %q", rule, param))
+		return nil, false
+	}
+	return parts, true
+}
@@ -0,0 +1,219 @@
+package devify
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// queryRecorder is a database/sql connector that records the queries it gets
+// and answers them with a row when found is set.
+type queryRecorder struct {
+	query string
+	args  []driver.Value
+	found bool
+	err   error
+}
+
+func (q *queryRecorder) Connect(context.Context) (driver.Conn, error) { return recorderConn{q}, nil }
+func (q *queryRecorder) Driver() driver.Driver                        { return nil }
+
+type recorderConn struct{ q *queryRecorder }
+
+func (c recorderConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c recorderConn) Close() error                        { return nil }
+func (c recorderConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c recorderConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.q.query = query
+	c.q.args = nil
+	for _, arg := range args {
+		c.q.args = append(c.q.args, arg.Value)
+	}
+	if c.q.err != nil {
+		return nil, c.q.err
+	}
+	return &recorderRows{found: c.q.found}, nil
+}
+
+type recorderRows struct{ found bool }
+
+func (r *recorderRows) Columns() []string { return []string{"1"} }
+func (r *recorderRows) Close() error      { return nil }
+
+func (r *recorderRows) Next(dest []driver.Value) error {
+	if !r.found {
+		return io.EOF
+	}
+	r.found = false
+	dest[0] = int64(1)
+	return nil
+}
+
+// dbValidation returns a Validation of data querying a queryRecorder as a
+// database of type dataType.
+func dbValidation(t *testing.T, dataType string, data url.Values) (*Validation, *queryRecorder) {
+	t.Helper()
+	q := &queryRecorder{}
+	db := sql.OpenDB(q)
+	t.Cleanup(func() { _ = db.Close() })
+
+	v := newValidation(data)
+	v.db = Database{DataType: dataType, Pool: db}
+	return v, q
+}
+
+func TestValidation_DatabaseRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		dataType string
+		rules    func(v *Validation)
+		query    string
+		args     []driver.Value
+	}{
+		{"unique on postgres", "postgres", func(v *Validation) { v.Unique("email", "users", "email", nil) },
+			`SELECT 1 FROM "users" WHERE "email" = $1 LIMIT 1`, []driver.Value{"ada@example.com"}},
+		{"unique on pgx ignoring an ID", "pgx", func(v *Validation) { v.Unique("email", "users", "email", 7) },
+			`SELECT 1 FROM "users" WHERE "email" = $1 AND "id" <> $2 LIMIT 1`, []driver.Value{"ada@example.com", int64(7)}},
+		{"unique on mysql", "mysql", func(v *Validation) { v.Unique("email", "users", "email", 7) },
+			"SELECT 1 FROM `users` WHERE `email` = ? AND `id` <> ? LIMIT 1", []driver.Value{"ada@example.com", int64(7)}},
+		{"unique on mariadb with a schema", "mariadb", func(v *Validation) { v.Unique("email", "auth.users", "email", nil) },
+			"SELECT 1 FROM `auth`.`users` WHERE `email` = ? LIMIT 1", []driver.Value{"ada@example.com"}},
+		{"unique on sqlite", "sqlite", func(v *Validation) { v.Unique("email", "users", "email", nil) },
+			`SELECT 1 FROM "users" WHERE "email" = ? LIMIT 1`, []driver.Value{"ada@example.com"}},
+		{"zero ignored ID", "postgres", func(v *Validation) { v.Unique("email", "users", "email", 0) },
+			`SELECT 1 FROM "users" WHERE "email" = $1 LIMIT 1`, []driver.Value{"ada@example.com"}},
+		{"ID column", "postgres", func(v *Validation) { v.IDColumn("uuid").Unique("email", "users", "email", "u-1") },
+			`SELECT 1 FROM "users" WHERE "email" = $1 AND "uuid" <> $2 LIMIT 1`, []driver.Value{"ada@example.com", "u-1"}},
+		{"soft deletes", "postgres", func(v *Validation) { v.SoftDeletes("deleted_at").Unique("email", "users", "email", 7) },
+			`SELECT 1 FROM "users" WHERE "email" = $1 AND "id" <> $2 AND "deleted_at" IS NULL LIMIT 1`, []driver.Value{"ada@example.com", int64(7)}},
+		{"exists", "mysql", func(v *Validation) { v.SoftDeletes("deleted_at").Exists("category_id", "categories", "id") },
+			"SELECT 1 FROM `categories` WHERE `id` = ? AND `deleted_at` IS NULL LIMIT 1", []driver.Value{"3"}},
+
+		{"unique rule", "postgres", func(v *Validation) { v.Rule("email", "unique", []string{"users", "email"}) },
+			`SELECT 1 FROM "users" WHERE "email" = $1 LIMIT 1`, []driver.Value{"ada@example.com"}},
+		{"unique rule with an ID", "postgres", func(v *Validation) { v.Rule("email", "unique", []string{"users", "email", "9"}) },
+			`SELECT 1 FROM "users" WHERE "email" = $1 AND "id" <> $2 LIMIT 1`, []driver.Value{"ada@example.com", "9"}},
+		{"unique rule with an ID field", "postgres", func(v *Validation) { v.Rule("email", "unique", []string{"users", "email", "user_id"}) },
+			`SELECT 1 FROM "users" WHERE "email" = $1 AND "id" <> $2 LIMIT 1`, []driver.Value{"ada@example.com", "42"}},
+		{"unique rule with an ID column", "mysql", func(v *Validation) {
+			v.Rule("email", "unique", []string{"users", "email", "user_id", "user_id"})
+		}, "SELECT 1 FROM `users` WHERE `email` = ? AND `user_id` <> ? LIMIT 1", []driver.Value{"ada@example.com", "42"}},
+		{"unique rule uses IDColumn", "postgres", func(v *Validation) {
+			v.IDColumn("uuid").Rule("email", "unique", []string{"users", "email", "u-1"})
+		}, `SELECT 1 FROM "users" WHERE "email" = $1 AND "uuid" <> $2 LIMIT 1`, []driver.Value{"ada@example.com", "u-1"}},
+		{"exists rule", "postgres", func(v *Validation) { v.Rule("category_id", "exists", []string{"categories", "id"}) },
+			`SELECT 1 FROM "categories" WHERE "id" = $1 LIMIT 1`, []driver.Value{"3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, q := dbValidation(t, tt.dataType, url.Values{
+				"email":       {"ada@example.com"},
+				"category_id": {"3"},
+				"user_id":     {"42"},
+			})
+			tt.rules(v)
+
+			if v.Err() != nil {
+				t.Fatalf("Err = %v", v.Err())
+			}
+			if q.query != tt.query {
+				t.Errorf("query = %s; want %s", q.query, tt.query)
+			}
+			if !reflect.DeepEqual(q.args, tt.args) {
+				t.Errorf("args = %#v; want %#v", q.args, tt.args)
+			}
+		})
+	}
+}
+
+func TestValidation_DatabaseResults(t *testing.T) {
+	tests := []struct {
+		name   string
+		found  bool
+		rules  func(v *Validation)
+		errors int
+	}{
+		{"unique and taken", true, func(v *Validation) { v.Unique("email", "users", "email", nil) }, 1},
+		{"unique and free", false, func(v *Validation) { v.Unique("email", "users", "email", nil) }, 0},
+		{"exists and found", true, func(v *Validation) { v.Exists("email", "users", "email") }, 0},
+		{"exists and missing", false, func(v *Validation) { v.Exists("email", "users", "email") }, 1},
+		{"empty values are not checked", false, func(v *Validation) { v.Exists("missing", "users", "email") }, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, q := dbValidation(t, "postgres", url.Values{"email": {"ada@example.com"}})
+			q.found = tt.found
+			tt.rules(v)
+
+			if len(v.Errors) != tt.errors || v.Err() != nil {
+				t.Errorf("Errors = %v, Err = %v; want %d errors", v.Errors, v.Err(), tt.errors)
+			}
+		})
+	}
+}
+
+func TestValidation_DatabaseRuleErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules func(v *Validation)
+		want  string // part of Err
+	}{
+		{"invalid table", func(v *Validation) { v.Unique("email", "users; DROP TABLE users", "email", nil) }, "invalid table or column"},
+		{"invalid column", func(v *Validation) { v.Exists("email", "users", `email"`) }, "invalid table or column"},
+		{"invalid ID column", func(v *Validation) { v.IDColumn("id--").Unique("email", "users", "email", 1) }, "invalid ID column"},
+		{"invalid soft-delete column", func(v *Validation) { v.SoftDeletes("a b").Exists("email", "users", "email") }, "invalid soft-delete column"},
+		{"rule without a column", func(v *Validation) { v.Rule("email", "unique", []string{"users"}) }, "needs a table and a column"},
+		{"rule with too many params", func(v *Validation) { v.Rule("email", "exists", []string{"users", "email", "1"}) }, "needs a table and a column"},
+		{"query error", func(v *Validation) { v.Unique("email", "users", "email", nil) }, "connection refused"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, q := dbValidation(t, "postgres", url.Values{"email": {"ada@example.com"}})
+			q.err = errors.New("connection refused")
+			tt.rules(v)
+
+			if v.Valid() {
+				t.Error("Valid = true; want false")
+			}
+			if v.Err() == nil || !strings.Contains(v.Err().Error(), tt.want) {
+				t.Errorf("Err = %v; want it to contain %q", v.Err(), tt.want)
+			}
+		})
+	}
+
+	v := newValidation(url.Values{"email": {"ada@example.com"}})
+	if v.Unique("email", "users", "email", nil); v.Err() == nil {
+		t.Error("Err = nil without a database")
+	}
+}
+
+func TestValidateStruct_DatabaseRules(t *testing.T) {
+	d := newTestApp(t)
+	q := &queryRecorder{found: true}
+	d.DB = Database{DataType: "postgres", Pool: sql.OpenDB(q)}
+	t.Cleanup(func() { _ = d.DB.Pool.Close() })
+
+	err := d.ValidateStruct(&struct {
+		ID    int    `json:"id"`
+		Email string `json:"email" validate:"unique=users email id"`
+	}{ID: 5, Email: "ada@example.com"})
+
+	var v *Validation
+	if !errors.As(err, &v) || v.Errors["email"] == "" || v.Err() != nil {
+		t.Fatalf("ValidateStruct = %v; want an email error", err)
+	}
+	want := `SELECT 1 FROM "users" WHERE "email" = $1 AND "id" <> $2 LIMIT 1`
+	if q.query != want || !reflect.DeepEqual(q.args, []driver.Value{"ada@example.com", "5"}) {
+		t.Errorf("query = %s %v; want %s", q.query, q.args, want)
+	}
+}
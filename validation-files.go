@@ -0,0 +1,129 @@
+package devify
+
+import (
+	"image"
+	_ "image/gif"  // register GIF for ImageDimensions
+	_ "image/jpeg" // register JPEG for ImageDimensions
+	_ "image/png"  // register PNG for ImageDimensions
+	"mime/multipart"
+	"strconv"
+	"strings"
+)
+
+// Dimensions bounds the size of images for ImageDimensions, in pixels. Zero
+// fields are not checked.
+type Dimensions struct {
+	MinWidth, MinHeight int
+	MaxWidth, MaxHeight int
+}
+
+// files returns the files uploaded in field, if the request is a multipart form.
+func (v *Validation) files(field string) []*multipart.FileHeader {
+	if v.Req == nil || v.Req.MultipartForm == nil {
+		return nil
+	}
+	return v.Req.MultipartForm.File[field]
+}
+
+// IsFile ensures at least one file was uploaded in field.
+// It adds a custom or default error if there is none.
+func (v *Validation) IsFile(field string, message ...string) *Validation {
+	defaultMsg := v.message("file", "Must be a file.")
+	if len(message) > 0 {
+		defaultMsg = message[0]
+	}
+	if len(v.files(field)) == 0 {
+		v.AddError(field, defaultMsg)
+	}
+	return v
+}
+
+// MaxFileSize ensures every file uploaded in field is at most max bytes.
+// It adds a custom or default error if one is larger.
+func (v *Validation) MaxFileSize(field string, max int64, message ...string) *Validation {
+	defaultMsg := v.message("max_file_size", "Must not be larger than {size}.", "size", formatBytes(max))
+	if len(message) > 0 {
+		defaultMsg = message[0]
+	}
+	for _, header := range v.files(field) {
+		if header.Size > max {
+			v.AddError(field, defaultMsg)
+			break
+		}
+	}
+	return v
+}
+
+// MimeTypes ensures every file uploaded in field has one of types, detected
+// from its content rather than its name or the type the client sent. Types
+// may use wildcards, as in "image/*". It adds a custom or default error if a
+// file has another type.
+func (v *Validation) MimeTypes(field string, types []string, message ...string) *Validation {
+	defaultMsg := v.message("mime_types", "Must be a file of type: {types}.", "types", strings.Join(types, ", "))
+	if len(message) > 0 {
+		defaultMsg = message[0]
+	}
+	for _, header := range v.files(field) {
+		contentType, err := detectContentType(header)
+		if err != nil {
+			v.setErr(err)
+			return v
+		}
+		if !mimeAllowed(contentType, types) {
+			v.AddError(field, defaultMsg)
+			break
+		}
+	}
+	return v
+}
+
+// ImageDimensions ensures every file uploaded in field is a GIF, JPEG or PNG
+// image within dims. It adds a custom or default error if a file is not such
+// an image or is too small or too large.
+//
+// Example:
+//
+//	v.ImageDimensions("avatar", devify.Dimensions{MinWidth: 100, MinHeight: 100, MaxWidth: 2000, MaxHeight: 2000})
+func (v *Validation) ImageDimensions(field string, dims Dimensions, message ...string) *Validation {
+	defaultMsg := v.message("image_dimensions", "Must be an image of valid dimensions.")
+	if len(message) > 0 {
+		defaultMsg = message[0]
+	}
+	for _, header := range v.files(field) {
+		if !dims.fit(header) {
+			v.AddError(field, defaultMsg)
+			break
+		}
+	}
+	return v
+}
+
+// fit reports whether the uploaded file is an image within d.
+func (d Dimensions) fit(header *multipart.FileHeader) bool {
+	f, err := header.Open()
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	config, _, err := image.DecodeConfig(f)
+	if err != nil {
+		return false
+	}
+	return config.Width >= d.MinWidth && config.Height >= d.MinHeight &&
+		(d.MaxWidth == 0 || config.Width <= d.MaxWidth) &&
+		(d.MaxHeight == 0 || config.Height <= d.MaxHeight)
+}
+
+// formatBytes formats n bytes for messages, e.g. "2MB".
+func formatBytes(n int64) string {
+	for _, unit := range []struct {
+		size int64
+		name string
+	}{{1 << 30, "GB"}, {1 << 20, "MB"}, {1 << 10, "KB"}} {
+		if n >= unit.size && n%unit.size == 0 {
+			return strconv.FormatInt(n/unit.size, 10) + unit.name
+		}
+	}
+	return strconv.FormatInt(n, 10) + " bytes"
+}
@@ -0,0 +1,234 @@
+package devify
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RuleFunc reports whether value, the field's value as submitted, passes a
+// rule. params are the parameters the rule was applied with: the arguments of
+// Validation.Rule, or the space-separated text after "=" in a validate tag.
+type RuleFunc func(v *Validation, field, value string, params []string) bool
+
+var (
+	rulesMu     sync.RWMutex
+	customRules = map[string]structRule{}
+)
+
+// RegisterRule adds a rule named name, usable with Validation.Rule and in
+// validate tags. message is the default error, translated through the
+// validation.<name> catalog key; {field} is replaced by the field name,
+// {param} by the first parameter and {params} by all of them. A registered
+// rule replaces a built-in one with the same name. Register rules while
+// setting up the application.
+//
+// Example:
+//
+//	devify.RegisterRule("slug", "Must contain only lowercase letters, digits and dashes.",
+//	    func(v *devify.Validation, field, value string, params []string) bool {
+//	        return slugPattern.MatchString(value)
+//	    })
+//
+//	v.Rule("handle", "slug", nil)
+//	// or, in a struct: Handle string `json:"handle" validate:"required,slug"`
+func RegisterRule(name, message string, fn RuleFunc) {
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+
+	customRules[name] = func(v *Validation, path string, _ reflect.Value, param string) {
+		params := strings.Fields(param)
+		if !fn(v, path, v.Data.Get(path), params) {
+			first := ""
+			if len(params) > 0 {
+				first = params[0]
+			}
+			v.AddError(path, v.message(name, message, "field", path, "param", first, "params", strings.Join(params, ", ")))
+		}
+	}
+}
+
+// lookupRule returns the registered or built-in rule named name.
+func lookupRule(name string) (structRule, bool) {
+	rulesMu.RLock()
+	rule, ok := customRules[name]
+	rulesMu.RUnlock()
+	if ok {
+		return rule, true
+	}
+	rule, ok = structRules[name]
+	return rule, ok
+}
+
+// Rule applies the rule named name, registered with RegisterRule or one of
+// the built-in validate tag rules, to field with params. It adds message
+// instead of the rule's default error when given. An unknown name is reported
+// by Err and makes v invalid.
+//
+// Example:
+//
+//	v.Rule("handle", "slug", nil)
+//	v.Rule("plan", "oneof", []string{"free", "pro"}, "Pick a plan.")
+func (v *Validation) Rule(field, name string, params []string, message ...string) *Validation {
+	rule, ok := lookupRule(name)
+	if !ok {
+		v.setErr(fmt.Errorf("devify: unknown validation rule %q", name))
+		return v
+	}
+
+	_, failed := v.Errors[field]
+	rule(v, field, reflect.ValueOf(v.Data.Get(field)), strings.Join(params, " "))
+	if _, failedNow := v.Errors[field]; failedNow && !failed && len(message) > 0 {
+		v.Errors[field] = message[0]
+	}
+	return v
+}
+
+// When runs rules on v only if cond is true.
+//
+// Example:
+//
+//	v.When(v.Data.Get("shipping") == "express", func(v *devify.Validation) {
+//	    v.RequiredFields([]string{"phone"}).IsPhone("phone")
+//	})
+func (v *Validation) When(cond bool, rules func(v *Validation)) *Validation {
+	if cond {
+		rules(v)
+	}
+	return v
+}
+
+// Sometimes runs rules on v only if field was submitted, even empty, so
+// optional fields are checked only when present.
+func (v *Validation) Sometimes(field string, rules func(v *Validation)) *Validation {
+	if _, ok := v.Data[field]; ok {
+		rules(v)
+	}
+	return v
+}
+
+// Same ensures field has the same value as other.
+// It adds a custom or default error if the values differ.
+func (v *Validation) Same(field, other string, message ...string) *Validation {
+	defaultMsg := v.message("same", "Must match {other}.", "other", other)
+	if len(message) > 0 {
+		defaultMsg = message[0]
+	}
+	if v.Data.Get(field) != v.Data.Get(other) {
+		v.AddError(field, defaultMsg)
+	}
+	return v
+}
+
+// Different ensures field does not have the same value as other.
+// It adds a custom or default error if the values are equal.
+func (v *Validation) Different(field, other string, message ...string) *Validation {
+	defaultMsg := v.message("different", "Must be different from {other}.", "other", other)
+	if len(message) > 0 {
+		defaultMsg = message[0]
+	}
+	if v.Data.Get(field) == v.Data.Get(other) {
+		v.AddError(field, defaultMsg)
+	}
+	return v
+}
+
+// Confirmed ensures field matches its <field>_confirmation companion, as in
+// password and password_confirmation. The error is added to field.
+func (v *Validation) Confirmed(field string, message ...string) *Validation {
+	defaultMsg := v.message("confirmed", "The confirmation does not match.")
+	if len(message) > 0 {
+		defaultMsg = message[0]
+	}
+	if v.Data.Get(field) != v.Data.Get(field+"_confirmation") {
+		v.AddError(field, defaultMsg)
+	}
+	return v
+}
+
+// RequiredIf ensures field is present and non-empty when other has value.
+func (v *Validation) RequiredIf(field, other, value string, message ...string) *Validation {
+	if strings.TrimSpace(v.Data.Get(other)) == value {
+		v.RequiredFields([]string{field}, message...)
+	}
+	return v
+}
+
+// RequiredWith ensures field is present and non-empty when any of others is.
+func (v *Validation) RequiredWith(field string, others []string, message ...string) *Validation {
+	for _, other := range others {
+		if v.Has(other) {
+			return v.RequiredFields([]string{field}, message...)
+		}
+	}
+	return v
+}
+
+// After ensures field holds a date after date, which is the name of another
+// field, a date in one of the IsDate formats, "today" or "now". Empty or
+// invalid dates in field get the IsDate error.
+func (v *Validation) After(field, date string, message ...string) *Validation {
+	return v.compareDate(field, date, true, message)
+}
+
+// Before ensures field holds a date before date; see After.
+func (v *Validation) Before(field, date string, message ...string) *Validation {
+	return v.compareDate(field, date, false, message)
+}
+
+func (v *Validation) compareDate(field, date string, after bool, message []string) *Validation {
+	value, ok := parseDate(v.Data.Get(field))
+	if !ok {
+		return v.IsDate(field)
+	}
+
+	bound := date
+	if _, isField := v.Data[date]; isField {
+		bound = v.Data.Get(date)
+	}
+	limit, ok := parseDate(bound)
+	if !ok {
+		// Nothing to compare against; the other field has its own rules.
+		return v
+	}
+
+	defaultMsg := v.message("before", "Must be a date before {date}.", "date", date)
+	if after {
+		defaultMsg = v.message("after", "Must be a date after {date}.", "date", date)
+	}
+	if len(message) > 0 {
+		defaultMsg = message[0]
+	}
+	if (after && !value.After(limit)) || (!after && !value.Before(limit)) {
+		v.AddError(field, defaultMsg)
+	}
+	return v
+}
+
+// parseDate parses value in one of dateFormats, or as "today" or "now".
+func parseDate(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	switch value {
+	case "now":
+		return time.Now(), true
+	case "today":
+		y, m, d := time.Now().Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC), true
+	}
+	for _, format := range dateFormats {
+		if t, err := time.Parse(format, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// sibling returns the path of the field name next to the field at path.
+func sibling(path, name string) string {
+	if i := strings.LastIndex(path, "."); i >= 0 {
+		return path[:i+1] + name
+	}
+	return name
+}
@@ -0,0 +1,154 @@
+package devify
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newValidation returns a Validation of data, without a request.
+func newValidation(data url.Values) *Validation {
+	return &Validation{Data: data, Errors: make(map[string]string)}
+}
+
+func TestValidation_Rules(t *testing.T) {
+	RegisterRule("slug", "{field} must be a slug.", func(v *Validation, field, value string, params []string) bool {
+		return value != "" && strings.Trim(value, "abcdefghijklmnopqrstuvwxyz0123456789-") == ""
+	})
+	RegisterRule("prefixed", "Must start with {param}.", func(v *Validation, field, value string, params []string) bool {
+		for _, p := range params {
+			if strings.HasPrefix(value, p) {
+				return true
+			}
+		}
+		return false
+	})
+
+	data := url.Values{
+		"handle":                {"Not A Slug"},
+		"code":                  {"ZZ-1"},
+		"plan":                  {"pro"},
+		"password":              {"secret"},
+		"password_confirmation": {"secret"},
+		"email":                 {"ada@example.com"},
+		"username":              {"ada@example.com"},
+		"starts_at":             {"2025-01-01"},
+		"ends_at":               {"2025-02-01"},
+		"present_but_empty":     {""},
+	}
+
+	tests := []struct {
+		name  string
+		rules func(v *Validation)
+		want  map[string]string
+	}{
+		{"custom rule", func(v *Validation) { v.Rule("handle", "slug", nil) },
+			map[string]string{"handle": "handle must be a slug."}},
+		{"custom rule passes", func(v *Validation) { v.Rule("code", "prefixed", []string{"AA", "ZZ"}) }, nil},
+		{"custom rule with params", func(v *Validation) { v.Rule("code", "prefixed", []string{"AA", "BB"}) },
+			map[string]string{"code": "Must start with AA."}},
+		{"custom message", func(v *Validation) { v.Rule("handle", "slug", nil, "Pick another handle.") },
+			map[string]string{"handle": "Pick another handle."}},
+		{"built-in rule", func(v *Validation) { v.Rule("plan", "oneof", []string{"free", "team"}, "Pick a plan.") },
+			map[string]string{"plan": "Pick a plan."}},
+		{"message keeps an earlier error", func(v *Validation) {
+			v.AddError("handle", "first")
+			v.Rule("handle", "slug", nil, "second")
+		}, map[string]string{"handle": "first"}},
+
+		{"same", func(v *Validation) { v.Same("password_confirmation", "password") }, nil},
+		{"same fails", func(v *Validation) { v.Same("email", "plan") }, map[string]string{"email": "Must match plan."}},
+		{"different", func(v *Validation) { v.Different("username", "email", "Use another username.") },
+			map[string]string{"username": "Use another username."}},
+		{"confirmed", func(v *Validation) { v.Confirmed("password") }, nil},
+		{"confirmed without confirmation", func(v *Validation) { v.Confirmed("email") },
+			map[string]string{"email": "The confirmation does not match."}},
+		{"required_if applies", func(v *Validation) { v.RequiredIf("company", "plan", "pro") },
+			map[string]string{"company": "This field is required."}},
+		{"required_if skips", func(v *Validation) { v.RequiredIf("company", "plan", "free") }, nil},
+		{"required_with applies", func(v *Validation) { v.RequiredWith("vat", []string{"company", "email"}) },
+			map[string]string{"vat": "This field is required."}},
+		{"required_with skips", func(v *Validation) { v.RequiredWith("vat", []string{"company"}) }, nil},
+		{"after a field", func(v *Validation) { v.After("ends_at", "starts_at") }, nil},
+		{"before a field", func(v *Validation) { v.Before("ends_at", "starts_at") },
+			map[string]string{"ends_at": "Must be a date before starts_at."}},
+		{"after a date", func(v *Validation) { v.After("starts_at", "2025-06-01") },
+			map[string]string{"starts_at": "Must be a date after 2025-06-01."}},
+		{"before today", func(v *Validation) { v.Before("starts_at", "today") }, nil},
+		{"after an invalid date", func(v *Validation) { v.After("plan", "today") },
+			map[string]string{"plan": "Must be a valid date (e.g., YYYY-MM-DD or MM/DD/YYYY)."}},
+
+		{"when true", func(v *Validation) {
+			v.When(true, func(v *Validation) { v.RequiredFields([]string{"phone"}) })
+		}, map[string]string{"phone": "This field is required."}},
+		{"when false", func(v *Validation) {
+			v.When(false, func(v *Validation) { v.RequiredFields([]string{"phone"}) })
+		}, nil},
+		{"sometimes present", func(v *Validation) {
+			v.Sometimes("present_but_empty", func(v *Validation) { v.RequiredFields([]string{"present_but_empty"}) })
+		}, map[string]string{"present_but_empty": "This field is required."}},
+		{"sometimes absent", func(v *Validation) {
+			v.Sometimes("phone", func(v *Validation) { v.RequiredFields([]string{"phone"}) })
+		}, nil},
+
+		{"required fields", func(v *Validation) { v.RequiredFields([]string{"email", "phone"}, "Needed.") },
+			map[string]string{"phone": "Needed."}},
+		{"deprecated required takes a trailing message", func(v *Validation) { v.Required("phone", "Needed.") },
+			map[string]string{"phone": "Needed."}},
+		{"deprecated required with a message-like field", func(v *Validation) { v.Required("phone", "fax") },
+			map[string]string{"phone": "fax"}},
+		{"deprecated required with one field", func(v *Validation) { v.Required("phone") },
+			map[string]string{"phone": "This field is required."}},
+		{"one of", func(v *Validation) { v.OneOf("plan", []string{"free", "team"}) },
+			map[string]string{"plan": "Must be one of: free, team."}},
+		{"deprecated in takes a trailing message", func(v *Validation) { v.In("plan", "free", "team", "Pick.") },
+			map[string]string{"plan": "Pick."}},
+		{"deprecated in with a message-like option", func(v *Validation) { v.In("plan", "free", "pro") },
+			map[string]string{"plan": "pro"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := newValidation(data)
+			tt.rules(v)
+
+			if tt.want == nil {
+				tt.want = map[string]string{}
+			}
+			if !reflect.DeepEqual(v.Errors, tt.want) {
+				t.Errorf("Errors = %v; want %v", v.Errors, tt.want)
+			}
+			if v.Err() != nil {
+				t.Errorf("Err = %v", v.Err())
+			}
+		})
+	}
+}
+
+func TestValidation_UnknownRule(t *testing.T) {
+	v := newValidation(url.Values{"handle": {"x"}})
+	v.Rule("handle", "no-such-rule", nil)
+
+	if v.Valid() {
+		t.Error("Valid = true; want false")
+	}
+	if v.Err() == nil || !strings.Contains(v.Err().Error(), `unknown validation rule "no-such-rule"`) {
+		t.Errorf("Err = %v", v.Err())
+	}
+}
+
+func TestRegisterRule_InTags(t *testing.T) {
+	RegisterRule("even", "Must be even.", func(v *Validation, field, value string, params []string) bool {
+		return strings.TrimLeft(value, "0123456789") == "" && strings.ContainsAny(value[len(value)-1:], "02468")
+	})
+
+	d := newTestApp(t)
+	err := d.ValidateStruct(&struct {
+		Count int `json:"count" validate:"even"`
+	}{Count: 3})
+	v, ok := err.(*Validation)
+	if !ok || v.Errors["count"] != "Must be even." {
+		t.Errorf("ValidateStruct = %v; want a count error", err)
+	}
+}
@@ -1,15 +1,17 @@
 package devify
 
 import (
-	"fmt"
+	"context"
 	"net/http"
 	"net/mail"
 	"net/url"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/jorgeSader/devify/i18n"
 	"github.com/nyaruka/phonenumbers"
 )
 
@@ -18,23 +20,79 @@ type Validation struct {
 	Data   url.Values        // Form data from the request
 	Errors map[string]string // Validation errors keyed by field name
 	Req    *http.Request     // HTTP request for locale and form data
+
+	translator       *i18n.Translator
+	db               Database
+	ctx              context.Context
+	softDeleteColumn string
+	idColumn         string
+	err              error
 }
 
 // Validator creates a new Validation instance from an HTTP request.
-// It parses the request form and initializes the validation state.
+// It parses the request form, multipart forms included (up to
+// MAX_UPLOAD_SIZE, for the file rules), and initializes the validation state.
+//
+// Default error messages are translated into the request's locale through the
+// validation.* keys of the i18n catalogs (validation.required,
+// validation.min_length with {min}, and so on; see the messages below), and
+// fall back to English.
 func (d *Devify) Validator(r *http.Request) *Validation {
-	_ = r.ParseForm() // Ignoring error for simplicity; handle in production if needed
+	// Errors are ignored for simplicity; the fields are then reported as missing.
+	if isMultipart(r) {
+		_ = d.parseMultipart(r)
+	} else {
+		_ = r.ParseForm()
+	}
 	return &Validation{
-		Data:   r.Form,
-		Errors: make(map[string]string),
-		Req:    r,
+		Data:       r.Form,
+		Errors:     make(map[string]string),
+		Req:        r,
+		translator: d.I18n,
+		db:         d.DB,
+	}
+}
+
+// message returns the translation of the validation.<key> catalog entry in the
+// request's locale, or the English fallback. args fill {placeholders} in both.
+func (v *Validation) message(key, fallback string, args ...interface{}) string {
+	if v.translator != nil {
+		locale := v.translator.DefaultLocale()
+		if v.Req != nil {
+			if l := i18n.LocaleFrom(v.Req.Context()); l != "" {
+				locale = l
+			}
+		}
+		if msg, ok := v.translator.Lookup(locale, "validation."+key, args...); ok {
+			return msg
+		}
 	}
+	return i18n.Format(fallback, args...)
 }
 
 // Valid reports whether the validation has no errors.
-// It returns true if no validation errors exist, false otherwise.
+// It returns true if no validation errors exist and every rule could be
+// checked (see Err), false otherwise.
 func (v *Validation) Valid() bool {
-	return len(v.Errors) == 0
+	return len(v.Errors) == 0 && v.err == nil
+}
+
+// Error makes a failed Validation an error, so handlers adapted with Handle can
+// return it; WriteError turns it into a 422 response with the field errors.
+func (v *Validation) Error() string {
+	if v.err != nil {
+		return "validation failed: " + v.err.Error()
+	}
+	fields := make([]string, 0, len(v.Errors))
+	for field := range v.Errors {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	for i, field := range fields {
+		fields[i] = field + ": " + v.Errors[field]
+	}
+	return "validation failed: " + strings.Join(fields, "; ")
 }
 
 // AddError adds an error message for a field if it doesn’t already exist.
@@ -51,18 +109,29 @@ func (v *Validation) Has(field string) bool {
 	return strings.TrimSpace(v.Data.Get(field)) != ""
 }
 
-// Required ensures the specified fields are present and non-empty.
-// It adds a custom or default error for each field that is missing or empty.
+// Required ensures the specified fields are present and non-empty. When there
+// is more than one argument and the last one has no spaces, it is taken as
+// the error message instead of a field, so Required("email", "password")
+// only checks email.
+//
+// Deprecated: use RequiredFields, which takes the message separately.
 func (v *Validation) Required(fields ...string) *Validation {
-	message := "This field is required."
-	if len(fields) > 1 && fields[len(fields)-1] != "" && !strings.Contains(fields[len(fields)-1], " ") {
-		message = fields[len(fields)-1]
-		fields = fields[:len(fields)-1]
+	if last := len(fields) - 1; last > 0 && fields[last] != "" && !strings.Contains(fields[last], " ") {
+		return v.RequiredFields(fields[:last], fields[last])
+	}
+	return v.RequiredFields(fields)
+}
+
+// RequiredFields ensures fields are present and non-empty, adding the custom
+// or default error for each one that is missing or empty.
+func (v *Validation) RequiredFields(fields []string, message ...string) *Validation {
+	defaultMsg := v.message("required", "This field is required.")
+	if len(message) > 0 {
+		defaultMsg = message[0]
 	}
 	for _, field := range fields {
-		value := v.Data.Get(field)
-		if strings.TrimSpace(value) == "" {
-			v.AddError(field, message)
+		if !v.Has(field) {
+			v.AddError(field, defaultMsg)
 		}
 	}
 	return v
@@ -81,9 +150,9 @@ func (v *Validation) Check(ok bool, key, message string) *Validation {
 // It checks against RFC 5322 using mail.ParseAddress and adds a custom or default error if invalid.
 func (v *Validation) IsEmail(fieldName string, message ...string) *Validation {
 	email := strings.TrimSpace(v.Data.Get(fieldName))
-	defaultMsg := "Invalid email address."
+	defaultMsg := v.message("email", "Invalid email address.")
 	if email == "" {
-		defaultMsg = "Email cannot be empty."
+		defaultMsg = v.message("email_empty", "Email cannot be empty.")
 	}
 	if len(message) > 0 {
 		defaultMsg = message[0]
@@ -100,9 +169,9 @@ func (v *Validation) IsEmail(fieldName string, message ...string) *Validation {
 // An error (custom or default) is added if the number is invalid or not plausible.
 func (v *Validation) IsPhone(fieldName string, message ...string) *Validation {
 	phone := strings.TrimSpace(v.Data.Get(fieldName))
-	defaultMsg := "Invalid phone number format."
+	defaultMsg := v.message("phone", "Invalid phone number format.")
 	if phone == "" {
-		defaultMsg = "Phone number cannot be empty."
+		defaultMsg = v.message("phone_empty", "Phone number cannot be empty.")
 	}
 	if len(message) > 0 {
 		defaultMsg = message[0]
@@ -140,7 +209,7 @@ func (v *Validation) IsPhone(fieldName string, message ...string) *Validation {
 // It adds a custom or default error if the trimmed value is shorter than the minimum.
 func (v *Validation) MinLength(fieldName string, min int, message ...string) *Validation {
 	value := strings.TrimSpace(v.Data.Get(fieldName))
-	defaultMsg := "Must be at least " + strconv.Itoa(min) + " characters long."
+	defaultMsg := v.message("min_length", "Must be at least {min} characters long.", "min", min)
 	if len(message) > 0 {
 		defaultMsg = message[0]
 	}
@@ -154,7 +223,7 @@ func (v *Validation) MinLength(fieldName string, min int, message ...string) *Va
 // It adds a custom or default error if the trimmed value is longer than the maximum.
 func (v *Validation) MaxLength(fieldName string, max int, message ...string) *Validation {
 	value := strings.TrimSpace(v.Data.Get(fieldName))
-	defaultMsg := "Must not exceed " + strconv.Itoa(max) + " characters."
+	defaultMsg := v.message("max_length", "Must not exceed {max} characters.", "max", max)
 	if len(message) > 0 {
 		defaultMsg = message[0]
 	}
@@ -168,9 +237,9 @@ func (v *Validation) MaxLength(fieldName string, max int, message ...string) *Va
 // It adds a custom or default error if the value cannot be parsed as an integer.
 func (v *Validation) IsInt(fieldName string, message ...string) *Validation {
 	value := strings.TrimSpace(v.Data.Get(fieldName))
-	defaultMsg := "Must be an integer."
+	defaultMsg := v.message("int", "Must be an integer.")
 	if value == "" {
-		defaultMsg = "Value cannot be empty."
+		defaultMsg = v.message("empty", "Value cannot be empty.")
 	}
 	if len(message) > 0 {
 		defaultMsg = message[0]
@@ -190,9 +259,9 @@ func (v *Validation) IsInt(fieldName string, message ...string) *Validation {
 // It adds a custom or default error if the value cannot be parsed as a float.
 func (v *Validation) IsFloat(fieldName string, message ...string) *Validation {
 	value := strings.TrimSpace(v.Data.Get(fieldName))
-	defaultMsg := "Must be a number."
+	defaultMsg := v.message("float", "Must be a number.")
 	if value == "" {
-		defaultMsg = "Value cannot be empty."
+		defaultMsg = v.message("empty", "Value cannot be empty.")
 	}
 	if len(message) > 0 {
 		defaultMsg = message[0]
@@ -212,9 +281,9 @@ func (v *Validation) IsFloat(fieldName string, message ...string) *Validation {
 // It checks if the value is a valid URL with a scheme and adds a custom or default error if invalid.
 func (v *Validation) IsURL(fieldName string, message ...string) *Validation {
 	value := strings.TrimSpace(v.Data.Get(fieldName))
-	defaultMsg := "Must be a valid URL (e.g., https://example.com)."
+	defaultMsg := v.message("url", "Must be a valid URL (e.g., https://example.com).")
 	if value == "" {
-		defaultMsg = "URL cannot be empty."
+		defaultMsg = v.message("url_empty", "URL cannot be empty.")
 	}
 	if len(message) > 0 {
 		defaultMsg = message[0]
@@ -230,6 +299,14 @@ func (v *Validation) IsURL(fieldName string, message ...string) *Validation {
 	return v
 }
 
+// dateFormats are the layouts IsDate, After and Before accept.
+var dateFormats = []string{
+	"2006-01-02",       // YYYY-MM-DD
+	"01/02/2006",       // MM/DD/YYYY
+	"02/01/2006",       // DD/MM/YYYY
+	"2006-01-02 15:04", // YYYY-MM-DD HH:MM
+}
+
 // IsDate validates whether a field contains a valid date.
 // It checks the value against common formats (YYYY-MM-DD, MM/DD/YYYY, DD/MM/YYYY, YYYY-MM-DD HH:MM)
 // and adds an error if invalid or empty. With one variadic argument, it sets a custom error message.
@@ -237,9 +314,9 @@ func (v *Validation) IsURL(fieldName string, message ...string) *Validation {
 // Default error messages are "Must be a valid date (e.g., YYYY-MM-DD or MM/DD/YYYY)" or "Date cannot be empty."
 func (v *Validation) IsDate(fieldName string, message ...string) *Validation {
 	value := strings.TrimSpace(v.Data.Get(fieldName))
-	defaultMsg := "Must be a valid date (e.g., YYYY-MM-DD or MM/DD/YYYY)."
+	defaultMsg := v.message("date", "Must be a valid date (e.g., YYYY-MM-DD or MM/DD/YYYY).")
 	if value == "" {
-		defaultMsg = "Date cannot be empty."
+		defaultMsg = v.message("date_empty", "Date cannot be empty.")
 	}
 	if len(message) > 0 && message[0] != "" {
 		defaultMsg = message[0]
@@ -249,15 +326,10 @@ func (v *Validation) IsDate(fieldName string, message ...string) *Validation {
 		return v
 	}
 
-	formats := []string{
-		"2006-01-02",       // YYYY-MM-DD
-		"01/02/2006",       // MM/DD/YYYY
-		"02/01/2006",       // DD/MM/YYYY
-		"2006-01-02 15:04", // YYYY-MM-DD HH:MM
-	}
+	formats := dateFormats
 	if len(message) > 1 && message[1] != "" {
 		formats = []string{message[1]} // Use custom format if provided
-		defaultMsg = "Must match the format: " + message[1]
+		defaultMsg = v.message("date_format", "Must match the format: {format}", "format", message[1])
 	}
 
 	for _, format := range formats {
@@ -273,7 +345,7 @@ func (v *Validation) IsDate(fieldName string, message ...string) *Validation {
 // It adds a custom or default error if the value is outside the specified range.
 func (v *Validation) Between(fieldName string, min, max float64, message ...string) *Validation {
 	value := strings.TrimSpace(v.Data.Get(fieldName))
-	defaultMsg := fmt.Sprintf("Must be between %v and %v.", min, max)
+	defaultMsg := v.message("between", "Must be between {min} and {max}.", "min", min, "max", max)
 	if len(message) > 0 {
 		defaultMsg = message[0]
 	}
@@ -287,15 +359,27 @@ func (v *Validation) Between(fieldName string, min, max float64, message ...stri
 	return v
 }
 
-// In ensures a field’s value is one of the specified options.
-// It adds a custom or default error if the value is not in the list.
+// In ensures a field’s value is one of the specified options. When there is
+// more than one option and the last one has no spaces, it is taken as the
+// error message instead of an option, so In("plan", "free", "pro") only
+// accepts "free".
+//
+// Deprecated: use OneOf, which takes the message separately.
 func (v *Validation) In(fieldName string, options ...string) *Validation {
-	value := strings.TrimSpace(v.Data.Get(fieldName))
-	defaultMsg := "Must be one of: " + strings.Join(options[:len(options)-1], ", ") + "."
-	if len(options) > 1 && !strings.Contains(options[len(options)-1], " ") {
-		defaultMsg = options[len(options)-1]
-		options = options[:len(options)-1]
+	if last := len(options) - 1; last > 0 && !strings.Contains(options[last], " ") {
+		return v.OneOf(fieldName, options[:last], options[last])
 	}
+	return v.OneOf(fieldName, options)
+}
+
+// OneOf ensures a field’s value is one of options.
+// It adds a custom or default error if the value is not in the list.
+func (v *Validation) OneOf(fieldName string, options []string, message ...string) *Validation {
+	defaultMsg := v.message("in", "Must be one of: {options}.", "options", strings.Join(options, ", "))
+	if len(message) > 0 {
+		defaultMsg = message[0]
+	}
+	value := strings.TrimSpace(v.Data.Get(fieldName))
 	for _, opt := range options {
 		if value == opt {
 			return v
@@ -309,7 +393,7 @@ func (v *Validation) In(fieldName string, options ...string) *Validation {
 // It adds a custom or default error if the value does not match the pattern.
 func (v *Validation) Matches(fieldName, pattern string, message ...string) *Validation {
 	value := strings.TrimSpace(v.Data.Get(fieldName))
-	defaultMsg := "Does not match the required pattern."
+	defaultMsg := v.message("matches", "Does not match the required pattern.")
 	if len(message) > 0 {
 		defaultMsg = message[0]
 	}
@@ -324,9 +408,9 @@ func (v *Validation) Matches(fieldName, pattern string, message ...string) *Vali
 // Use this when spaces are not allowed (e.g., usernames, codes).
 func (v *Validation) HasNoSpaces(fieldName string, message ...string) *Validation {
 	value := v.Data.Get(fieldName) // Not trimming to catch all spaces
-	defaultMsg := "Must not contain spaces."
+	defaultMsg := v.message("no_spaces", "Must not contain spaces.")
 	if value == "" {
-		defaultMsg = "Value cannot be empty."
+		defaultMsg = v.message("empty", "Value cannot be empty.")
 	}
 	if len(message) > 0 {
 		defaultMsg = message[0]
@@ -345,9 +429,9 @@ func (v *Validation) HasNoSpaces(fieldName string, message ...string) *Validatio
 // It adds a custom or default error if the substring is not found in the value.
 func (v *Validation) Contains(fieldName, substring string, message ...string) *Validation {
 	value := strings.TrimSpace(v.Data.Get(fieldName))
-	defaultMsg := fmt.Sprintf("Must contain '%s'.", substring)
+	defaultMsg := v.message("contains", "Must contain '{substring}'.", "substring", substring)
 	if value == "" {
-		defaultMsg = "Value cannot be empty."
+		defaultMsg = v.message("empty", "Value cannot be empty.")
 	}
 	if len(message) > 0 {
 		defaultMsg = message[0]
@@ -369,9 +453,9 @@ func (v *Validation) Contains(fieldName, substring string, message ...string) *V
 // and adds a custom or default error if the value is invalid or empty.
 func (v *Validation) IsBoolean(fieldName string, message ...string) *Validation {
 	value := strings.TrimSpace(v.Data.Get(fieldName))
-	defaultMsg := "Must be a valid boolean value (true, false, 1, or 0)."
+	defaultMsg := v.message("boolean", "Must be a valid boolean value (true, false, 1, or 0).")
 	if value == "" {
-		defaultMsg = "Value cannot be empty."
+		defaultMsg = v.message("empty", "Value cannot be empty.")
 	}
 	if len(message) > 0 {
 		defaultMsg = message[0]